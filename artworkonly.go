@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"podcastdownload/internal/podcast"
+)
+
+// artworkShows lists the shows an --artwork-only input stands for: every
+// match of a search, every feed of an OPML file, or the podcast a feed URL
+// or ID names
+func artworkShows(input string, provider podcast.SearchProvider, opts options) ([]podcast.SearchResult, error) {
+	switch {
+	case podcast.IsOPMLFile(input):
+		return podcast.ReadOPML(input)
+	case isSearchTerm(input):
+		results, err := podcast.Search(input, provider)
+		if err != nil {
+			return nil, err
+		}
+		if opts.byAuthor != "" {
+			results = podcast.FilterByAuthor(results, opts.byAuthor)
+		}
+		if len(results) == 0 {
+			return nil, fmt.Errorf("%w for: %s", podcast.ErrNotFound, input)
+		}
+		return results, nil
+	}
+	info, _, err := podcast.Resolve(input, provider, opts.byAuthor)
+	if err != nil {
+		return nil, err
+	}
+	return []podcast.SearchResult{{Name: info.Name, Artist: info.Artist, FeedURL: info.FeedURL, ArtworkURL: info.ArtworkURL}}, nil
+}
+
+// runArtworkOnly saves the cover image of every show the inputs stand for,
+// named after the show, in the folder its episodes would go to. Shows
+// without artwork are skipped. It returns the number of shows whose
+// artwork couldn't be saved.
+func runArtworkOnly(inputs []string, baseDir string, provider podcast.SearchProvider, opts options) int {
+	fmt.Printf("Saving to %s\n", baseDir)
+	saved, noArtwork, failed := 0, 0, 0
+	written := make(map[string]bool) // shows listed twice keep the first cover
+	for _, input := range inputs {
+		fmt.Printf("==> %s\n", input)
+		shows, err := artworkShows(input, provider, opts)
+		if err != nil {
+			fmt.Printf("  ✗ %s: %v\n", input, err)
+			failed++
+			continue
+		}
+		for _, show := range shows {
+			// OPML lists give no artwork, and some index entries lack it;
+			// the feed names its own
+			if show.ArtworkURL == "" && show.FeedURL != "" {
+				info, _, err := podcast.LoadFeed(show.FeedURL, show.Name, show.Artist, "")
+				if err != nil {
+					fmt.Printf("  ✗ %s: %v\n", show.Name, err)
+					failed++
+					continue
+				}
+				show.Name, show.ArtworkURL = info.Name, info.ArtworkURL
+			}
+			if show.ArtworkURL == "" {
+				fmt.Printf("  - skipping %s: no artwork\n", show.Name)
+				noArtwork++
+				continue
+			}
+
+			art, err := podcast.FetchArtwork(show.ArtworkURL, opts.artworkJPEG)
+			if err == nil {
+				l := newLayout(podcast.PodcastInfo{Name: show.Name}, baseDir, 0, opts)
+				path := filepath.Join(l.dir, l.name+art.Ext())
+				if written[path] {
+					fmt.Printf("  - skipping %s: already saved from an earlier listing\n", show.Name)
+					continue
+				}
+				if err = os.MkdirAll(l.dir, 0755); err == nil {
+					err = os.WriteFile(path, art.Data, 0644)
+				}
+				if err == nil {
+					written[path] = true
+					fmt.Printf("  ✓ %s\n", path)
+					saved++
+					continue
+				}
+			}
+			fmt.Printf("  ✗ %s: %v\n", show.Name, err)
+			failed++
+		}
+	}
+
+	fmt.Printf("\n%d saved, %d without artwork, %d failed\n", saved, noArtwork, failed)
+	return failed
+}
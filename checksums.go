@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"podcastdownload/internal/podcast"
+)
+
+// checksumFile is the manifest --checksum-manifest keeps in each podcast
+// folder, in the format sha256sum -c reads
+const checksumFile = "checksums.sha256"
+
+// fileSHA256 hashes the file at path
+func fileSHA256(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// readChecksums reads the manifest in dir as file name to hex digest, with
+// the names in manifest order. A folder without a manifest has no entries.
+func readChecksums(dir string) (map[string]string, []string, error) {
+	sums := make(map[string]string)
+	f, err := os.Open(filepath.Join(dir, checksumFile))
+	if os.IsNotExist(err) {
+		return sums, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// "<digest>  <name>", or "<digest> *<name>" for binary mode
+		sum, name, ok := strings.Cut(scanner.Text(), " ")
+		if !ok || len(sum) != sha256.Size*2 {
+			continue
+		}
+		name = strings.TrimPrefix(strings.TrimPrefix(name, " "), "*")
+		if _, seen := sums[name]; !seen {
+			names = append(names, name)
+		}
+		sums[name] = strings.ToLower(sum)
+	}
+	return sums, names, scanner.Err()
+}
+
+// recordChecksum adds the file at path to the manifest in its folder, or
+// updates its entry. sum is the SHA-256 hashed while downloading; MP3s are
+// hashed again, since tagging rewrites them after the download.
+func recordChecksum(path string, sum []byte) error {
+	if sum == nil || podcast.IsMP3(path) {
+		var err error
+		if sum, err = fileSHA256(path); err != nil {
+			return err
+		}
+	}
+
+	dir, name := filepath.Dir(path), filepath.Base(path)
+	sums, names, err := readChecksums(dir)
+	if err != nil {
+		return err
+	}
+	if _, ok := sums[name]; !ok {
+		names = append(names, name)
+	}
+	sums[name] = hex.EncodeToString(sum)
+
+	// Write a new manifest and move it into place, so an interrupted
+	// write never leaves a truncated one
+	var b strings.Builder
+	for _, n := range names {
+		fmt.Fprintf(&b, "%s  %s\n", sums[n], n)
+	}
+	tmp := filepath.Join(dir, "."+checksumFile+".tmp")
+	if err := os.WriteFile(tmp, []byte(b.String()), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, checksumFile))
+}
+
+// refreshChecksum updates the manifest entry of a file whose tags were
+// rewritten, when its folder's manifest lists it
+func refreshChecksum(path string) error {
+	sums, _, err := readChecksums(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	if _, ok := sums[filepath.Base(path)]; !ok {
+		return nil
+	}
+	return recordChecksum(path, nil)
+}
+
+// checksumMismatch compares the file at path with its entry in the
+// manifest of its folder, and describes a difference. Files the manifest
+// doesn't list pass.
+func checksumMismatch(path string, manifests map[string]map[string]string) (string, error) {
+	dir := filepath.Dir(path)
+	sums, ok := manifests[dir]
+	if !ok {
+		var err error
+		if sums, _, err = readChecksums(dir); err != nil {
+			return "", err
+		}
+		manifests[dir] = sums
+	}
+	want, ok := sums[filepath.Base(path)]
+	if !ok {
+		return "", nil
+	}
+	got, err := fileSHA256(path)
+	if err != nil {
+		return "", err
+	}
+	if hex.EncodeToString(got) != want {
+		return "checksum mismatch", nil
+	}
+	return "", nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"podcastdownload/internal/podcast"
+)
+
+// column is one of the episode details the picker can show beside the title
+type column string
+
+const (
+	colIndex    column = "index"
+	colDate     column = "date"
+	colDuration column = "duration"
+	colSize     column = "size"
+	colExplicit column = "explicit"
+)
+
+var allColumns = []column{colIndex, colDate, colDuration, colSize, colExplicit}
+
+var defaultColumns = []column{colIndex, colDate, colDuration}
+
+// columnPresets are the sets of columns c cycles through in the picker
+var columnPresets = [][]column{
+	defaultColumns,
+	{colIndex, colDate, colSize},
+	allColumns,
+	{colIndex},
+}
+
+// parseColumns reads a comma-separated list of columns, e.g. index,date,size;
+// "none" leaves only the titles
+func parseColumns(s string) ([]column, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "none") {
+		return []column{}, nil
+	}
+	cols := []column{}
+	for _, name := range strings.Split(s, ",") {
+		c := column(strings.ToLower(strings.TrimSpace(name)))
+		if !slices.Contains(allColumns, c) {
+			return nil, fmt.Errorf("unknown column %q (want %s)", name, columnsString(allColumns))
+		}
+		if !slices.Contains(cols, c) {
+			cols = append(cols, c)
+		}
+	}
+	return cols, nil
+}
+
+// columnsString is the inverse of parseColumns
+func columnsString(cols []column) string {
+	if len(cols) == 0 {
+		return "none"
+	}
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ",")
+}
+
+// nextColumns is the preset after cols, or the first when cols isn't one
+func nextColumns(cols []column) []column {
+	for i, preset := range columnPresets {
+		if slices.Equal(preset, cols) {
+			return columnPresets[(i+1)%len(columnPresets)]
+		}
+	}
+	return columnPresets[0]
+}
+
+// columnCell is an episode's entry in a column, unpadded
+func columnCell(c column, ep podcast.Episode) string {
+	switch c {
+	case colIndex:
+		return strconv.Itoa(ep.Index)
+	case colDate:
+		if !ep.PubDate.IsZero() {
+			return ep.PubDate.Format("2006-01-02")
+		}
+	case colDuration:
+		return ep.Duration
+	case colSize:
+		if ep.ExpectedSize > 0 {
+			return formatSize(ep.ExpectedSize)
+		}
+	case colExplicit:
+		if ep.Explicit {
+			return "E"
+		}
+	}
+	return ""
+}
+
+// columnWidths measures each column over every episode, not just the ones
+// on screen, so the list doesn't shift as it scrolls. A column no episode
+// has anything for gets no width, and isn't drawn.
+func columnWidths(cols []column, episodes []podcast.Episode) map[column]int {
+	widths := make(map[column]int, len(cols))
+	for _, c := range cols {
+		for _, ep := range episodes {
+			widths[c] = max(widths[c], len(columnCell(c, ep)))
+		}
+	}
+	return widths
+}
+
+// settingsFile keeps choices made in the TUI between runs, in the same
+// folder as podcastConfigFile
+const settingsFile = "settings.json"
+
+// settings is what the TUI remembers
+type settings struct {
+	Columns string `json:"columns,omitempty"` // the picker's columns, as --columns
+}
+
+// loadSettings reads the saved settings; without a file there are none
+func loadSettings() (settings, error) {
+	var s settings
+	path, err := configPath(settingsFile)
+	if err != nil {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// saveSettings writes the settings, creating the config folder if needed
+func saveSettings(s settings) error {
+	path, err := configPath(settingsFile)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
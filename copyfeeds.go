@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/muesli/termenv"
+
+	"podcastdownload/internal/podcast"
+)
+
+// copyText puts text on the clipboard. Without a clipboard tool, such as
+// over SSH, it asks the terminal to do it with OSC 52, which can't report
+// whether it worked; sent tells the two apart.
+func copyText(text string) (sent bool) {
+	if err := clipboard.WriteAll(text); err == nil {
+		return false
+	}
+	termenv.Copy(text)
+	return true
+}
+
+// copyFeeds copies the feed URLs of the listed search results, one per
+// line for --stdin, or as an OPML subscription list
+func (m model) copyFeeds(asOPML bool) model {
+	var urls []string
+	for _, r := range m.searchResults {
+		if r.FeedURL != "" {
+			urls = append(urls, r.FeedURL)
+		}
+	}
+	if len(urls) == 0 {
+		m.notice = "No feed URLs to copy"
+		return m
+	}
+
+	text := strings.Join(urls, "\n") + "\n"
+	format := "feed URL(s)"
+	if asOPML {
+		doc, err := podcast.OPML(fmt.Sprintf("Search results: %s", m.searchQuery), m.searchResults)
+		if err != nil {
+			m.notice = "Couldn't build the OPML: " + err.Error()
+			return m
+		}
+		text, format = string(doc), "feed(s) as OPML"
+	}
+
+	if copyText(text) {
+		m.notice = fmt.Sprintf("Sent %d %s to the terminal's clipboard", len(urls), format)
+	} else {
+		m.notice = fmt.Sprintf("Copied %d %s to the clipboard", len(urls), format)
+	}
+	if missing := len(m.searchResults) - len(urls); missing > 0 {
+		m.notice += fmt.Sprintf(" (%d without a feed)", missing)
+	}
+	return m
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newDirInput returns the text field for editing the base directory
+func newDirInput(t theme) textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "  › "
+	ti.PromptStyle = t.selected
+	ti.CharLimit = 4096
+	ti.Cursor.SetMode(cursor.CursorStatic) // the model doesn't relay blink messages
+	return ti
+}
+
+func (m model) handleEditDirKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.state = stateSelecting
+		m.dirErr = ""
+		return m, nil
+
+	case "enter":
+		dir, err := expandPath(strings.TrimSpace(m.dirInput.Value()))
+		if err == nil && dir == "" {
+			err = fmt.Errorf("directory can't be empty")
+		}
+		if err == nil {
+			err = checkWritable(dir)
+		}
+		if err != nil {
+			m.dirErr = err.Error()
+			return m, nil
+		}
+		m.baseDir = dir
+		m.dirErr = ""
+		m.state = stateSelecting
+		return m, nil
+
+	case "tab":
+		m.dirInput.SetValue(completePath(m.dirInput.Value()))
+		m.dirInput.CursorEnd()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.dirInput, cmd = m.dirInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewEditDir() string {
+	var b strings.Builder
+
+	b.WriteString("\n")
+	b.WriteString(m.theme.title.Render("Output Directory"))
+	b.WriteString("\n\n")
+	b.WriteString(m.theme.subtitle.Render("  The podcast folder is created inside this directory."))
+	b.WriteString("\n\n")
+	b.WriteString(m.dirInput.View())
+	b.WriteString("\n")
+
+	if m.dirErr != "" {
+		b.WriteString("\n  " + m.theme.error.Render(m.dirErr) + "\n")
+	}
+
+	b.WriteString(m.theme.help.Render("\n  tab complete • enter save • esc cancel"))
+
+	return b.String()
+}
+
+// completePath extends a partly typed path to the longest prefix shared by
+// the directories it could name, adding a separator once it is unambiguous
+func completePath(input string) string {
+	expanded, err := expandPath(input)
+	if err != nil {
+		return input
+	}
+
+	dir, partial := filepath.Split(expanded)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return input
+	}
+
+	var matches []string
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), partial) {
+			if strings.HasPrefix(entry.Name(), ".") && !strings.HasPrefix(partial, ".") {
+				continue // hidden directories only when asked for
+			}
+			matches = append(matches, entry.Name())
+		}
+	}
+	if len(matches) == 0 {
+		return input
+	}
+
+	common := matches[0]
+	for _, name := range matches[1:] {
+		// Shorten by whole runes, so names sharing a UTF-8 lead byte
+		// don't complete to half a character
+		for !strings.HasPrefix(name, common) {
+			_, size := utf8.DecodeLastRuneInString(common)
+			common = common[:len(common)-size]
+		}
+	}
+	if len(matches) == 1 {
+		common += string(filepath.Separator)
+	}
+
+	// Keep what the user typed and append only the completed part
+	return input + strings.TrimPrefix(common, partial)
+}
@@ -0,0 +1,13 @@
+package main
+
+import "syscall"
+
+// freeDiskSpace returns the bytes available to the current user on the
+// filesystem holding path; OpenBSD names the Statfs_t fields differently
+func freeDiskSpace(path string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return int64(st.F_bavail) * int64(st.F_bsize), nil
+}
@@ -0,0 +1,11 @@
+//go:build !(linux || darwin || freebsd || openbsd || windows)
+
+package main
+
+import "errors"
+
+// freeDiskSpace is not implemented on this platform; the preflight check
+// treats the free space as unknown
+func freeDiskSpace(path string) (int64, error) {
+	return 0, errors.ErrUnsupported
+}
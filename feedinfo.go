@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"podcastdownload/internal/podcast"
+)
+
+// feedProfile is the show-level metadata printed by --feed-only
+type feedProfile struct {
+	Title          string    `json:"title"`
+	Author         string    `json:"author"`
+	FeedURL        string    `json:"feed_url"`
+	MovedFrom      string    `json:"moved_from,omitempty"`
+	NewFeedURL     string    `json:"new_feed_url,omitempty"` // itunes:new-feed-url
+	Description    string    `json:"description"`
+	Language       string    `json:"language"`
+	Categories     []string  `json:"categories"`
+	Funding        []funding `json:"funding,omitempty"` // podcast:funding
+	Value          string    `json:"value,omitempty"`   // podcast:value, summarized
+	LastBuild      time.Time `json:"last_build_date,omitzero"`
+	Artwork        string    `json:"artwork"`
+	Episodes       int       `json:"episode_count"`
+	FirstEpisode   time.Time `json:"first_episode,omitzero"`
+	LatestEpisode  time.Time `json:"latest_episode,omitzero"`
+	UpdateInterval float64   `json:"update_interval_days,omitempty"` // median days between episodes
+}
+
+// funding is a podcast:funding link in a feedProfile
+type funding struct {
+	URL  string `json:"url"`
+	Text string `json:"text,omitempty"`
+}
+
+// newFeedProfile gathers the profile of a loaded podcast
+func newFeedProfile(info podcast.PodcastInfo, episodes []podcast.Episode) feedProfile {
+	p := feedProfile{
+		Title:       info.Name,
+		Author:      info.Artist,
+		FeedURL:     info.FeedURL,
+		MovedFrom:   info.MovedFrom,
+		NewFeedURL:  info.NewFeedURL,
+		Description: info.Description,
+		Language:    info.Language,
+		Categories:  info.Categories,
+		LastBuild:   info.LastBuild,
+		Artwork:     info.ArtworkURL,
+		Episodes:    len(episodes),
+	}
+	for _, f := range info.Funding {
+		p.Funding = append(p.Funding, funding{URL: f.URL, Text: f.Text})
+	}
+	if info.Value != nil {
+		p.Value = info.Value.Describe()
+	}
+	if p.Categories == nil {
+		p.Categories = []string{}
+	}
+	for _, ep := range episodes {
+		if ep.PubDate.IsZero() {
+			continue
+		}
+		if p.FirstEpisode.IsZero() || ep.PubDate.Before(p.FirstEpisode) {
+			p.FirstEpisode = ep.PubDate
+		}
+		if ep.PubDate.After(p.LatestEpisode) {
+			p.LatestEpisode = ep.PubDate
+		}
+	}
+	if interval, ok := podcast.UpdateInterval(episodes); ok {
+		p.UpdateInterval = interval.Hours() / 24
+	}
+	return p
+}
+
+// frequency describes an update interval in words
+func frequency(days float64) string {
+	switch {
+	case days == 0:
+		return "unknown"
+	case days < 1:
+		return fmt.Sprintf("about every %.0f hours", days*24)
+	case days < 1.5:
+		return "daily"
+	case days >= 6 && days <= 8:
+		return "weekly"
+	case days >= 13 && days <= 15:
+		return "every two weeks"
+	case days >= 28 && days <= 31:
+		return "monthly"
+	}
+	return fmt.Sprintf("about every %.0f days", days)
+}
+
+// printFeedProfile writes p as aligned text
+func printFeedProfile(w io.Writer, p feedProfile) {
+	date := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format("2006-01-02 15:04 MST")
+	}
+	orDash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+
+	fmt.Fprintf(w, "Title:        %s\n", orDash(p.Title))
+	fmt.Fprintf(w, "Author:       %s\n", orDash(p.Author))
+	fmt.Fprintf(w, "Feed:         %s\n", orDash(p.FeedURL))
+	if p.MovedFrom != "" {
+		fmt.Fprintf(w, "Moved from:   %s\n", p.MovedFrom)
+	}
+	if p.NewFeedURL != "" {
+		fmt.Fprintf(w, "Moved to:     %s (itunes:new-feed-url)\n", p.NewFeedURL)
+	}
+	fmt.Fprintf(w, "Language:     %s\n", orDash(p.Language))
+	fmt.Fprintf(w, "Categories:   %s\n", orDash(strings.Join(p.Categories, ", ")))
+	for _, f := range p.Funding {
+		fmt.Fprintf(w, "Support:      %s\n", fundingLine(podcast.Funding{URL: f.URL, Text: f.Text}))
+	}
+	if p.Value != "" {
+		fmt.Fprintf(w, "Value4Value:  %s\n", p.Value)
+	}
+	fmt.Fprintf(w, "Last build:   %s\n", date(p.LastBuild))
+	fmt.Fprintf(w, "Artwork:      %s\n", orDash(p.Artwork))
+	fmt.Fprintf(w, "Episodes:     %d", p.Episodes)
+	if !p.FirstEpisode.IsZero() {
+		fmt.Fprintf(w, " (%s to %s)", p.FirstEpisode.Format("2006-01-02"), p.LatestEpisode.Format("2006-01-02"))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Updates:      %s\n", frequency(p.UpdateInterval))
+
+	if p.Description != "" {
+		fmt.Fprintln(w, "\nDescription:")
+		for _, line := range wrapText(p.Description, 76) {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+}
+
+// runFeedOnly prints the profile of the podcast behind input without
+// listing or downloading episodes. It returns 1 when the podcast can't be
+// loaded.
+func runFeedOnly(input string, provider podcast.SearchProvider, opts options, asJSON bool) int {
+	info, episodes, err := podcast.Resolve(input, provider, opts.byAuthor)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	profile := newFeedProfile(info, episodes)
+	if !asJSON {
+		printFeedProfile(os.Stdout, profile)
+		return 0
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(profile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
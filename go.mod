@@ -3,11 +3,17 @@ module podcastdownload
 go 1.25.5
 
 require (
+	github.com/atotto/clipboard v0.1.4
 	github.com/bogem/id3v2 v1.2.0
 	github.com/charmbracelet/bubbles v0.21.0
 	github.com/charmbracelet/bubbletea v1.3.10
 	github.com/charmbracelet/lipgloss v1.1.0
 	github.com/mmcdole/gofeed v1.3.0
+	github.com/muesli/termenv v0.16.0
+	golang.org/x/image v0.25.0
+	golang.org/x/net v0.4.0
+	golang.org/x/sys v0.36.0
+	golang.org/x/time v0.15.0
 )
 
 require (
@@ -30,10 +36,7 @@ require (
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/muesli/ansi v0.0.0-20230316100256-276c6243b2f6 // indirect
 	github.com/muesli/cancelreader v0.2.2 // indirect
-	github.com/muesli/termenv v0.16.0 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
-	golang.org/x/net v0.4.0 // indirect
-	golang.org/x/sys v0.36.0 // indirect
-	golang.org/x/text v0.5.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
 )
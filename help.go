@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyBinding is one row of the help overlay
+type keyBinding struct {
+	keys   string
+	action string
+}
+
+// stateKeys lists the keys each screen responds to, in the order the README
+// documents them
+var stateKeys = map[state][]keyBinding{
+	stateSearchResults: {
+		{"↑/k ↓/j", "Move cursor"},
+		{"enter", "Select podcast"},
+		{"v", "Preview podcast metadata"},
+		{"d", "Hide the result from the list"},
+		{"u", "Restore the last hidden result"},
+		{"c", "Copy the listed feed URLs, one per line"},
+		{"C", "Copy the listed feeds as OPML"},
+		{"/", "Start a new search"},
+		{"q", "Quit"},
+	},
+	statePreviewPodcast: {
+		{"esc/b/v", "Go back to search results"},
+		{"q", "Quit"},
+	},
+	stateSelecting: {
+		{"↑/k ↓/j", "Move cursor"},
+		{"pgup/pgdown", "Move a page"},
+		{"space/x", "Toggle episode selection"},
+		{"a", "Select/deselect all episodes"},
+		{"i", "Invert the selection"},
+		{"#", "Toggle episodes by number, e.g. 1-5,12,20"},
+		{"s", "Cycle sort order (date, title, duration)"},
+		{"r", "Reverse the sort order"},
+		{"c", "Cycle the columns beside the titles"},
+		{"v", "Preview episode metadata"},
+		{"o", "Change the output directory"},
+		{"n", "Load the feed's new URL, when the publisher announces a move"},
+		{"m", "Load older episodes, when browsing --recent"},
+		{"enter", "Start downloading selected"},
+		{"esc/b", "Go back to search results"},
+		{"q", "Quit"},
+	},
+	statePreviewEpisode: {
+		{"↑/k ↓/j", "Scroll"},
+		{"pgup/pgdown", "Scroll a page"},
+		{"p", "Stream the episode in an external player"},
+		{"esc/b/v", "Go back to episode selection"},
+		{"q", "Quit"},
+	},
+	stateConfirm: {
+		{"enter/y", "Start downloading"},
+		{"esc/b/n", "Go back to episode selection"},
+		{"q", "Quit"},
+	},
+	stateDownloading: {
+		{"p", "Pause after the current episode, or resume"},
+		{"esc/b", "Go back to episode selection"},
+		{"q", "Cancel and quit"},
+	},
+	stateDone: {
+		{"r", "Retry the episodes that failed or weren't attempted"},
+		{"o", "Open the output folder in the file manager"},
+		{"enter/q", "Exit"},
+	},
+	stateError: {
+		{"/", "Start a new search"},
+		{"enter/q", "Exit"},
+	},
+}
+
+// hasHelp reports whether ? opens the help overlay on screen s. Screens
+// with a text field take ? as input, and so have no overlay.
+func hasHelp(s state) bool {
+	_, ok := stateKeys[s]
+	return ok
+}
+
+// openHelp shows the help overlay over the current screen
+func (m model) openHelp() (tea.Model, tea.Cmd) {
+	m.helpReturn = m.state
+	m.state = stateHelp
+	return m, nil
+}
+
+func (m model) handleHelpKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "?", "esc", "q", "enter":
+		m.state = m.helpReturn
+	}
+	return m, nil
+}
+
+func (m model) viewHelp() string {
+	var b strings.Builder
+
+	b.WriteString("\n")
+	b.WriteString(m.theme.title.Render("Keyboard shortcuts"))
+	b.WriteString("\n\n")
+
+	bindings := stateKeys[m.helpReturn]
+	width := 0
+	for _, k := range bindings {
+		width = max(width, len([]rune(k.keys)))
+	}
+	for _, k := range bindings {
+		b.WriteString(fmt.Sprintf("  %-*s   %s\n", width, k.keys, k.action))
+	}
+	b.WriteString(m.theme.dim.Render("\n  ctrl+c quits from any screen"))
+
+	b.WriteString(m.theme.help.Render("\n\n  ?/esc close help"))
+
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"slices"
+
+	"podcastdownload/internal/podcast"
+)
+
+// hiddenResult is a search result taken out of the list, and where it was
+type hiddenResult struct {
+	result podcast.SearchResult
+	index  int
+}
+
+// hideResult removes the result under the cursor from the list; it stays
+// in m.hiddenResults for unhideResult
+func (m model) hideResult(visibleItems int) model {
+	if m.cursor >= len(m.searchResults) {
+		return m
+	}
+	m.hiddenResults = append(m.hiddenResults, hiddenResult{result: m.searchResults[m.cursor], index: m.cursor})
+	m.searchResults = slices.Delete(slices.Clone(m.searchResults), m.cursor, m.cursor+1)
+	m.cursor = min(m.cursor, max(len(m.searchResults)-1, 0))
+	return m.scrollToCursor(visibleItems)
+}
+
+// unhideResult puts the most recently hidden result back where it was and
+// moves the cursor to it
+func (m model) unhideResult(visibleItems int) model {
+	if len(m.hiddenResults) == 0 {
+		return m
+	}
+	last := m.hiddenResults[len(m.hiddenResults)-1]
+	m.hiddenResults = m.hiddenResults[:len(m.hiddenResults)-1]
+	m.cursor = min(last.index, len(m.searchResults))
+	m.searchResults = slices.Insert(slices.Clone(m.searchResults), m.cursor, last.result)
+	return m.scrollToCursor(visibleItems)
+}
+
+// scrollToCursor adjusts the offset so the cursor is on screen
+func (m model) scrollToCursor(visibleItems int) model {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+visibleItems {
+		m.offset = m.cursor - visibleItems + 1
+	}
+	m.offset = max(min(m.offset, len(m.searchResults)-visibleItems), 0)
+	return m
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"podcastdownload/internal/podcast"
+)
+
+// historyShown is how many entries --history prints
+const historyShown = 20
+
+// historyEntry is one line of the download history
+type historyEntry struct {
+	Time    time.Time `json:"time"`
+	Podcast string    `json:"podcast"`
+	Title   string    `json:"title"`
+	URL     string    `json:"url"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`            // the file as recorded, tags included
+	Audio   int64     `json:"audio,omitempty"` // Size less the ID3v2 tag, for --verify
+}
+
+// historyPath is the history file
+func historyPath() (string, error) {
+	return dataPath("history.jsonl")
+}
+
+// dataPath is where the named data file lives, under $XDG_DATA_HOME or
+// ~/.local/share
+func dataPath(name string) (string, error) {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataDir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataDir, "podcast-go", name), nil
+}
+
+// recordDownload appends a downloaded episode to the history, with the file's
+// size once tagged. Each entry is written with a single append so concurrent
+// runs don't interleave lines.
+func recordDownload(info podcast.PodcastInfo, ep podcast.Episode, filePath string) error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	if abs, err := filepath.Abs(filePath); err == nil {
+		filePath = abs
+	}
+	fi, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	audio, err := podcast.AudioSize(filePath)
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(historyEntry{
+		Time:    time.Now(),
+		Podcast: info.Name,
+		Title:   ep.Title,
+		URL:     ep.AudioURL,
+		Path:    filePath,
+		Size:    fi.Size(),
+		Audio:   audio,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readHistory returns every history entry, oldest first; there are none
+// before the first download
+func readHistory() ([]historyEntry, error) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []historyEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // skip a torn or hand-edited line
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// printHistory prints the last n history entries, oldest first
+func printHistory(w io.Writer, n int) error {
+	entries, err := readHistory()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No downloads recorded yet.")
+		return nil
+	}
+	entries = entries[max(len(entries)-n, 0):]
+
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s  %-8s  %s — %s\n", e.Time.Local().Format("2006-01-02 15:04"), formatSize(e.Size), e.Podcast, e.Title)
+		fmt.Fprintf(w, "    %s\n", e.Path)
+	}
+	return nil
+}
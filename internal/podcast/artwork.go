@@ -0,0 +1,188 @@
+package podcast
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // decoders for image.Decode
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ArtworkOriginal is the artwork size asked for by --artwork-size original:
+// the largest Apple accepts for podcast artwork
+const ArtworkOriginal = 3000
+
+// AppleArtworkSize is the width in pixels of the Apple artwork to use; 0
+// keeps the 600px image Apple lists
+var AppleArtworkSize int
+
+// appleArtworkDimensions matches the size at the end of an Apple artwork
+// URL, e.g. .../600x600bb.jpg
+var appleArtworkDimensions = regexp.MustCompile(`/(\d+)x\d+([a-z]*\.(?:jpg|jpeg|png|webp))$`)
+
+// ParseArtworkSize validates an --artwork-size value: a width in pixels,
+// or "original"
+func ParseArtworkSize(s string) (int, error) {
+	if strings.EqualFold(s, "original") {
+		return ArtworkOriginal, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 || n > ArtworkOriginal {
+		return 0, fmt.Errorf("invalid artwork size %q (expected a width in pixels up to %d, or original)", s, ArtworkOriginal)
+	}
+	return n, nil
+}
+
+// appleArtworkURL picks the Apple artwork URL for AppleArtworkSize. Apple
+// serves its images at any size by rewriting the dimensions in the path,
+// so the listed 600px URL is rewritten; 100px is the fallback when Apple
+// lists no 600px image.
+func appleArtworkURL(url100, url600 string) string {
+	artwork := url600
+	if artwork == "" {
+		artwork = url100
+	}
+	if AppleArtworkSize == 0 || !appleArtworkDimensions.MatchString(artwork) {
+		return artwork
+	}
+	return appleArtworkResized(artwork, AppleArtworkSize)
+}
+
+// appleArtworkResized rewrites the dimensions of an Apple artwork URL
+func appleArtworkResized(url string, size int) string {
+	s := strconv.Itoa(size)
+	return appleArtworkDimensions.ReplaceAllString(url, "/"+s+"x"+s+"$2")
+}
+
+// LargestArtworkURL is the URL of the largest version of the artwork at
+// url: the ArtworkOriginal size for Apple artwork, which comes in any size.
+// Other URLs are returned unchanged.
+func LargestArtworkURL(url string) string {
+	if !appleArtworkDimensions.MatchString(url) {
+		return url
+	}
+	return appleArtworkResized(url, ArtworkOriginal)
+}
+
+// ArtworkWidth is the width in pixels an Apple artwork URL asks for, or 0
+// for other URLs
+func ArtworkWidth(url string) int {
+	m := appleArtworkDimensions.FindStringSubmatch(url)
+	if m == nil {
+		return 0
+	}
+	width, _ := strconv.Atoi(m[1])
+	return width
+}
+
+// maxArtworkSize caps how much of an artwork download is read
+const maxArtworkSize = 20 << 20
+
+// Artwork is a cover image ready to embed in tags
+type Artwork struct {
+	Data     []byte
+	MIMEType string
+}
+
+// Ext is the file extension for the image's format, e.g. .jpg
+func (a Artwork) Ext() string {
+	format := strings.TrimPrefix(a.MIMEType, "image/")
+	if format == "jpeg" {
+		return ".jpg"
+	}
+	return "." + format
+}
+
+// FetchArtwork downloads the cover image at url. With toJPEG, formats older
+// players don't display, such as WebP, are transcoded to JPEG; JPEG and PNG
+// are always kept as they are. Images that can't be decoded for transcoding,
+// AVIF among them, are an error, so no cover is embedded.
+func FetchArtwork(url string, toJPEG bool) (Artwork, error) {
+	resp, err := get(url)
+	if err != nil {
+		return Artwork{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Artwork{}, fmt.Errorf("artwork download failed: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxArtworkSize))
+	if err != nil {
+		return Artwork{}, asNetworkError(err)
+	}
+
+	art := Artwork{Data: data, MIMEType: imageType(data)}
+	switch {
+	case !strings.HasPrefix(art.MIMEType, "image/"):
+		return Artwork{}, fmt.Errorf("artwork is not an image (%s)", art.MIMEType)
+	case art.MIMEType == "image/jpeg", art.MIMEType == "image/png", !toJPEG:
+		return art, nil
+	}
+
+	return art.JPEG()
+}
+
+// JPEG converts the image to JPEG, putting transparent areas on white. A
+// JPEG is returned as it is.
+func (a Artwork) JPEG() (Artwork, error) {
+	if a.MIMEType == "image/jpeg" {
+		return a, nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(a.Data))
+	if err != nil {
+		return Artwork{}, fmt.Errorf("can't convert %s artwork to JPEG: %w", a.MIMEType, err)
+	}
+	return encodeJPEG(img, img.Bounds().Dx(), img.Bounds().Dy())
+}
+
+// Scaled shrinks the image to width pixels wide, as JPEG, keeping its
+// proportions. An image no wider is returned as it is.
+func (a Artwork) Scaled(width int) (Artwork, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(a.Data))
+	if err != nil {
+		return Artwork{}, fmt.Errorf("can't scale %s artwork: %w", a.MIMEType, err)
+	}
+	if width <= 0 || cfg.Width <= width {
+		return a, nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(a.Data))
+	if err != nil {
+		return Artwork{}, fmt.Errorf("can't scale %s artwork: %w", a.MIMEType, err)
+	}
+	return encodeJPEG(img, width, max(cfg.Height*width/cfg.Width, 1))
+}
+
+// encodeJPEG draws img at width by height pixels on white and encodes it
+func encodeJPEG(img image.Image, width, height int) (Artwork, error) {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	if img.Bounds().Size() == dst.Bounds().Size() {
+		draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Over, nil)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 90}); err != nil {
+		return Artwork{}, err
+	}
+	return Artwork{Data: buf.Bytes(), MIMEType: "image/jpeg"}, nil
+}
+
+// imageType sniffs the format of an image, adding AVIF, which
+// http.DetectContentType doesn't know
+func imageType(data []byte) string {
+	if len(data) >= 12 && string(data[4:8]) == "ftyp" && (string(data[8:12]) == "avif" || string(data[8:12]) == "avis") {
+		return "image/avif"
+	}
+	return http.DetectContentType(data)
+}
@@ -0,0 +1,51 @@
+package podcast
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// sessionCookies keeps the cookies servers set for the life of the process,
+// so a session cookie issued with a feed is sent with the downloads of its
+// enclosures. Premium feeds use this to sign access to their media.
+var sessionCookies, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+
+// httpClient is the client shared by feed fetches, API calls and downloads
+var httpClient = &http.Client{Jar: sessionCookies, Transport: userAgentTransport{}}
+
+// userAgent names the program to the servers it talks to
+const userAgent = "PodcastDownload/1.0"
+
+// userAgentTransport is http.DefaultTransport, sending userAgent with
+// requests that don't set their own
+type userAgentTransport struct{}
+
+func (userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", userAgent)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// apiTimeout bounds a call to the Apple or Podcast Index APIs, from the
+// request to the last byte of the reply
+const apiTimeout = 30 * time.Second
+
+// feedAccept asks for a feed over anything else. Some hosts negotiate on
+// Accept and answer a client that doesn't send it with 406 or an HTML page.
+const feedAccept = "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.9, */*;q=0.8"
+
+// newFeedRequest builds the request that fetches a feed
+func newFeedRequest(ctx context.Context, feedURL string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", feedAccept)
+	return req, nil
+}
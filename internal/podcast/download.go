@@ -0,0 +1,762 @@
+package podcast
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/bogem/id3v2"
+	"golang.org/x/time/rate"
+)
+
+// DefaultBufferSize is how much is read from the connection at a time,
+// and buffered before writing, unless DownloadOptions.BufferSize says
+// otherwise
+const DefaultBufferSize = 32 * 1024
+
+// DownloadOptions tunes a single Download
+type DownloadOptions struct {
+	// OnProgress, if non-nil, is called with the completed fraction roughly
+	// every 1%
+	OnProgress func(float64)
+
+	// MaxRate caps this file's transfer rate in bytes per second; 0 is
+	// unlimited
+	MaxRate int64
+
+	// Limiter, if non-nil, is shared by all downloads to cap their combined
+	// rate
+	Limiter *rate.Limiter
+
+	// BufferSize is the size of the read buffer and of the buffered writer
+	// in front of the file; 0 uses DefaultBufferSize. Larger buffers mean
+	// fewer system calls on fast links.
+	BufferSize int
+
+	// OnExists decides what happens when the file is already there; the
+	// zero value skips it
+	OnExists ExistsPolicy
+
+	// MinSize is the smallest plausible episode in bytes; a smaller
+	// download is deleted and reported as ErrTooSmall. 0 accepts any size.
+	MinSize int64
+
+	// StallTimeout aborts a transfer that receives nothing for this long;
+	// it is then resumed, up to maxRetries times, before failing with
+	// ErrStalled. 0 waits indefinitely.
+	StallTimeout time.Duration
+
+	// Hash, if non-nil, is fed the file's bytes as they are written, so a
+	// checksum needs no second read. When a partial file is resumed, the
+	// part already on disk is hashed first.
+	Hash hash.Hash
+}
+
+// ExistsPolicy is what Download does with a file that already exists
+type ExistsPolicy string
+
+const (
+	ExistsSkip      ExistsPolicy = "skip"      // leave it, without contacting the server
+	ExistsCheck     ExistsPolicy = "check"     // compare sizes first and resume a partial file
+	ExistsOverwrite ExistsPolicy = "overwrite" // download it again
+)
+
+// ParseExistsPolicy validates an --on-exists value
+func ParseExistsPolicy(s string) (ExistsPolicy, error) {
+	for _, policy := range []ExistsPolicy{ExistsSkip, ExistsCheck, ExistsOverwrite} {
+		if strings.EqualFold(s, string(policy)) {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("unknown policy %q (available: skip, check, overwrite)", s)
+}
+
+// NewRateLimiter returns a limiter allowing bytesPerSec, suitable for
+// DownloadOptions.Limiter
+func NewRateLimiter(bytesPerSec int64) *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(bytesPerSec), DefaultBufferSize)
+}
+
+// waitN waits until l allows n bytes, asking in pieces no larger than its
+// burst, which a read into a large buffer can exceed
+func waitN(l *rate.Limiter, n int) {
+	for n > 0 {
+		chunk := min(n, l.Burst())
+		l.WaitN(context.Background(), chunk)
+		n -= chunk
+	}
+}
+
+// Download downloads url to filepath, returning the number of bytes written
+// (0 when an existing file is kept). With ExistsCheck, the server is asked
+// for the size before any body is requested: an existing file at least that
+// large is kept and a smaller one is resumed where it stops. A transfer
+// that stalls is resumed the same way. When both a per-file and a shared
+// rate limit are set, the more restrictive one applies.
+func Download(filepath string, url string, opts DownloadOptions) (int64, error) {
+	var offset int64
+	if fi, err := os.Stat(filepath); err == nil {
+		switch opts.OnExists {
+		case ExistsOverwrite:
+		case ExistsCheck:
+			remote := HeadContentLength(url)
+			if remote <= 0 || fi.Size() >= remote {
+				return 0, nil
+			}
+			offset = fi.Size()
+		default:
+			return 0, nil
+		}
+	}
+
+	var written int64
+	for attempt := 0; ; attempt++ {
+		n, err := downloadFrom(filepath, url, offset, opts)
+		written += n
+		if !errors.Is(err, ErrStalled) || attempt == maxRetries {
+			return written, err
+		}
+		// Carry on from what arrived before the stall
+		fi, statErr := os.Stat(filepath)
+		if statErr != nil {
+			return written, err
+		}
+		offset = fi.Size()
+	}
+}
+
+// downloadFrom makes one attempt at fetching url into filepath from offset
+// on, returning the bytes written. A stalled transfer returns ErrStalled.
+func downloadFrom(filepath string, url string, offset int64, opts DownloadOptions) (int64, error) {
+	// The watchdog cancels the request when no data arrives in time
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var stalled atomic.Bool
+	var watchdog *time.Timer
+	if opts.StallTimeout > 0 {
+		watchdog = time.AfterFunc(opts.StallTimeout, func() {
+			stalled.Store(true)
+			cancel()
+		})
+		defer watchdog.Stop()
+	}
+	stallErr := func(err error) error {
+		if stalled.Load() {
+			return fmt.Errorf("%w: no data for %s", ErrStalled, opts.StallTimeout)
+		}
+		return err
+	}
+
+	// While waiting for an answer, only the time each attempt spends on the
+	// server counts; a Retry-After wait between attempts is ours
+	if watchdog != nil {
+		ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			GetConn:              func(string) { watchdog.Reset(opts.StallTimeout) },
+			GotFirstResponseByte: func() { watchdog.Stop() },
+		})
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := doWith(httpClient, req)
+	if err != nil {
+		return 0, stallErr(err)
+	}
+	defer resp.Body.Close()
+	if watchdog != nil {
+		watchdog.Reset(opts.StallTimeout)
+	}
+
+	// Anything but the file, or the rest of it, leaves what's on disk alone,
+	// so a failed resume can be tried again later
+	var flags int
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return 0, fmt.Errorf("rate limited by server (HTTP %d)", resp.StatusCode)
+	case offset > 0 && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		return 0, nil // nothing past what we have
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+		flags = os.O_WRONLY | os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		// A server that ignores the range sends the whole file again
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		offset = 0
+	default:
+		return 0, fmt.Errorf("download failed: HTTP %d", resp.StatusCode)
+	}
+	file, err := os.OpenFile(filepath, flags, 0666)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	bufSize := opts.BufferSize
+	if bufSize <= 0 {
+		bufSize = DefaultBufferSize
+	}
+	out := bufio.NewWriterSize(file, bufSize)
+	var sink io.Writer = out
+	if opts.Hash != nil {
+		if err := hashPrefix(opts.Hash, filepath, offset); err != nil {
+			return 0, err
+		}
+		sink = io.MultiWriter(out, opts.Hash)
+	}
+
+	limiters := []*rate.Limiter{opts.Limiter}
+	if opts.MaxRate > 0 {
+		limiters = append(limiters, NewRateLimiter(opts.MaxRate))
+	}
+
+	totalSize := resp.ContentLength
+	if totalSize > 0 {
+		totalSize += offset
+	}
+	downloaded := int64(0)
+	lastPercent := float64(0)
+
+	buf := make([]byte, bufSize)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			// Data arrived, so the server isn't stalled; time spent held
+			// back by the rate limit is ours, and doesn't count against it
+			if watchdog != nil {
+				watchdog.Stop()
+			}
+			for _, l := range limiters {
+				if l != nil {
+					waitN(l, n)
+				}
+			}
+			if _, werr := sink.Write(buf[:n]); werr != nil {
+				return downloaded, werr
+			}
+			downloaded += int64(n)
+			if watchdog != nil {
+				watchdog.Reset(opts.StallTimeout)
+			}
+			if totalSize > 0 {
+				percent := float64(offset+downloaded) / float64(totalSize)
+				// Only send updates every 1% to avoid flooding
+				if percent-lastPercent >= 0.01 || percent >= 1.0 {
+					lastPercent = percent
+					if opts.OnProgress != nil {
+						opts.OnProgress(percent)
+					}
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Keep what arrived, for the resume
+			out.Flush()
+			return downloaded, stallErr(asNetworkError(err))
+		}
+	}
+	if err := out.Flush(); err != nil {
+		return downloaded, err
+	}
+
+	// Some CDNs answer 200 with an empty or stub body
+	if total := offset + downloaded; total < opts.MinSize {
+		file.Close()
+		os.Remove(filepath)
+		return 0, fmt.Errorf("%w: got %d bytes, expected at least %d", ErrTooSmall, total, opts.MinSize)
+	}
+
+	return downloaded, nil
+}
+
+// hashPrefix resets h and feeds it the first n bytes of the file at path,
+// the part a resumed download keeps
+func hashPrefix(h hash.Hash, path string, n int64) error {
+	h.Reset()
+	if n == 0 {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.CopyN(h, f, n)
+	return err
+}
+
+// EpisodeSize is the expected size of an episode's audio: the enclosure
+// length from the feed, or else what the server reports, or -1 if unknown
+func EpisodeSize(ep Episode) int64 {
+	if ep.ExpectedSize > 0 {
+		return ep.ExpectedSize
+	}
+	return HeadContentLength(ep.AudioURL)
+}
+
+// sizeCheckTimeout bounds each request asking a server for a file's size; a
+// server that doesn't answer in time leaves the size unknown
+const sizeCheckTimeout = 30 * time.Second
+
+// HeadContentLength asks the server for a file's size without downloading
+// it, returning -1 when the size is unknown. Servers that reject HEAD or
+// leave out the length are asked for the first byte instead, whose
+// Content-Range gives the full size.
+func HeadContentLength(url string) int64 {
+	ctx, cancel := context.WithTimeout(context.Background(), sizeCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return -1
+	}
+	resp, err := httpClient.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK && resp.ContentLength >= 0 {
+			return resp.ContentLength
+		}
+	}
+	return rangedContentLength(url)
+}
+
+// rangedContentLength gets the size of url from a one-byte ranged GET
+func rangedContentLength(url string) int64 {
+	ctx, cancel := context.WithTimeout(context.Background(), sizeCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return -1
+	}
+	req.Header.Set("Range", "bytes=0-0")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return -1
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		// Content-Range: bytes 0-0/12345
+		_, total, ok := strings.Cut(resp.Header.Get("Content-Range"), "/")
+		if size, err := strconv.ParseInt(total, 10, 64); ok && err == nil {
+			return size
+		}
+	case http.StatusOK:
+		// The range was ignored; closing early still spares the body
+		return resp.ContentLength
+	}
+	return -1
+}
+
+// PeopleTagDescription names the TXXX frame holding an episode's
+// podcast:person credits, e.g. "Jane Doe (host); John Roe (guest)"
+const PeopleTagDescription = "PEOPLE"
+
+// DefaultID3Version is the ID3v2 minor version AddID3Tags writes unless
+// told otherwise: 2.3, which older and in-car players read more reliably
+// than 2.4
+const DefaultID3Version = 3
+
+// ParseID3Version validates an --id3-version value, 2.3 or 2.4, and
+// returns the minor version
+func ParseID3Version(s string) (byte, error) {
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "v") {
+	case "2.3", "3":
+		return 3, nil
+	case "2.4", "4":
+		return 4, nil
+	}
+	return 0, fmt.Errorf("unsupported ID3 version %q (available: 2.3, 2.4)", s)
+}
+
+// TagOptions controls how AddID3Tags fills in tags
+type TagOptions struct {
+	ShowArtist bool     // always use the show's author as artist, ignoring episode authors
+	People     bool     // add the episode's podcast:person credits as a TXXX frame
+	Artwork    *Artwork // cover embedded as an APIC frame; nil embeds none
+	Version    byte     // ID3v2 minor version, 3 or 4; 0 is DefaultID3Version
+}
+
+// encoding is the text encoding for frames of a tag of the given version:
+// ID3v2.3 has no UTF-8, so UTF-16 keeps non-Latin text intact there
+func encoding(version byte) id3v2.Encoding {
+	if version == 4 {
+		return id3v2.EncodingUTF8
+	}
+	return id3v2.EncodingUTF16
+}
+
+// reencodeFrames switches the text frames already in tag, such as those the
+// publisher wrote, to enc. A UTF-8 frame is invalid in an ID3v2.3 tag, and
+// Latin-1 ones may hold text the player decodes differently.
+func reencodeFrames(tag *id3v2.Tag, enc id3v2.Encoding) {
+	for id, frames := range tag.AllFrames() {
+		changed := false
+		for i, f := range frames {
+			switch f := f.(type) {
+			case id3v2.TextFrame:
+				f.Encoding = enc
+				frames[i] = f
+			case id3v2.CommentFrame:
+				f.Encoding = enc
+				frames[i] = f
+			case id3v2.UserDefinedTextFrame:
+				f.Encoding = enc
+				frames[i] = f
+			case id3v2.UnsynchronisedLyricsFrame:
+				f.Encoding = enc
+				frames[i] = f
+			case id3v2.PictureFrame:
+				f.Encoding = enc
+				frames[i] = f
+			default:
+				continue
+			}
+			changed = true
+		}
+		if changed {
+			tag.DeleteFrames(id)
+			for _, f := range frames {
+				tag.AddFrame(id, f)
+			}
+		}
+	}
+}
+
+// AddID3Tags writes episode metadata to an MP3 file; other formats are left
+// untouched since an ID3 header would corrupt them. The artist is the
+// episode's own author when the feed gives one.
+func AddID3Tags(filepath string, ep Episode, info PodcastInfo, opts TagOptions) error {
+	if !IsMP3(filepath) {
+		return nil
+	}
+
+	tag, err := id3v2.Open(filepath, id3v2.Options{Parse: true})
+	if err != nil {
+		// Create new tag if file doesn't have one
+		tag = id3v2.NewEmptyTag()
+	}
+	defer tag.Close()
+
+	// Frame IDs depend on the version, so it is set before any is added
+	version := opts.Version
+	if version == 0 {
+		version = DefaultID3Version
+	}
+	tag.SetVersion(version)
+	enc := encoding(version)
+	tag.SetDefaultEncoding(enc)
+	reencodeFrames(tag, enc)
+
+	tag.SetTitle(ep.Title)
+	artist := info.Artist
+	if ep.Author != "" && !opts.ShowArtist {
+		artist = ep.Author
+	}
+	tag.SetArtist(artist)
+	tag.SetAlbum(info.Name)
+
+	// The show notes, as plain text, go in a comment
+	if notes := ep.PlainDescription(); notes != "" {
+		lang := tagLanguage(info.Language)
+		if lang == "" {
+			lang = "XXX" // unknown, as the standard asks
+		}
+		tag.AddCommentFrame(id3v2.CommentFrame{
+			Encoding: enc,
+			Language: lang,
+			Text:     notes,
+		})
+	}
+
+	// Set track number
+	trackFrame := id3v2.TextFrame{
+		Encoding: enc,
+		Text:     strconv.Itoa(ep.Index),
+	}
+	tag.AddFrame(tag.CommonID("Track number/Position in set"), trackFrame)
+
+	// TLEN is the length in milliseconds
+	if seconds := DurationSeconds(ep.Duration); seconds > 0 {
+		tag.AddTextFrame(tag.CommonID("Length"), enc, strconv.Itoa(seconds*1000))
+	}
+
+	// TLAN takes an ISO 639-2 code; the TXXX frame keeps the region
+	if info.Language != "" {
+		if code := tagLanguage(info.Language); code != "" {
+			tag.AddTextFrame(tag.CommonID("Language"), enc, code)
+		}
+		tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+			Encoding:    enc,
+			Description: LanguageTagDescription,
+			Value:       info.Language,
+		})
+	}
+
+	if opts.People && len(ep.People) > 0 {
+		credits := make([]string, len(ep.People))
+		for i, p := range ep.People {
+			credits[i] = fmt.Sprintf("%s (%s)", p.Name, p.Role)
+		}
+		tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+			Encoding:    enc,
+			Description: PeopleTagDescription,
+			Value:       strings.Join(credits, "; "),
+		})
+	}
+
+	if opts.Artwork != nil {
+		tag.AddAttachedPicture(id3v2.PictureFrame{
+			Encoding:    enc,
+			MimeType:    opts.Artwork.MIMEType,
+			PictureType: id3v2.PTFrontCover,
+			Description: "Cover",
+			Picture:     opts.Artwork.Data,
+		})
+	}
+
+	return tag.Save()
+}
+
+// ReadTags reads back the title, artist and track number AddID3Tags writes,
+// as an MP3 episode
+func ReadTags(filepath string) (Episode, error) {
+	tag, err := id3v2.Open(filepath, id3v2.Options{Parse: true})
+	if err != nil {
+		return Episode{}, err
+	}
+	defer tag.Close()
+
+	ep := Episode{
+		Title:     strings.TrimSpace(tag.Title()),
+		Author:    strings.TrimSpace(tag.Artist()),
+		AudioType: "audio/mpeg",
+	}
+	// The track may be given as "N/total"
+	track, _, _ := strings.Cut(tag.GetTextFrame(tag.CommonID("Track number/Position in set")).Text, "/")
+	ep.Index, _ = strconv.Atoi(strings.TrimSpace(track))
+	return ep, nil
+}
+
+// EpisodeFilename returns the file name an episode is saved under in dir,
+// behind prefix. The title is shortened further when the whole name would
+// pass the 255 bytes filesystems allow or the full path the system's
+// limit; the track number and extension are always kept.
+func EpisodeFilename(dir, prefix string, ep Episode) string {
+	number := fmt.Sprintf("%03d - ", ep.Index)
+	title := SanitizeFilename(ep.Title)
+	ext := AudioExtension(ep.AudioType, ep.AudioURL)
+
+	room := maxNameBytes
+	if abs, err := filepath.Abs(dir); err == nil {
+		room = min(room, maxPathLength()-len(abs)-1)
+	}
+	room -= len(prefix) + len(number) + len(ext)
+	if len(title) > room {
+		title = shorten(title, len(title), room)
+		if title == "" {
+			title = "episode"
+		}
+	}
+	return number + title + ext
+}
+
+// numberedFile matches the "NNN - " track prefix of an episode file name,
+// possibly behind a "Podcast - " prefix
+var numberedFile = regexp.MustCompile(`(?:^|- )(\d+) - `)
+
+// MatchEpisode finds the episode a previously downloaded file belongs to,
+// first by its title and then by the track number in its name
+func MatchEpisode(filename string, episodes []Episode) (Episode, bool) {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	for _, ep := range episodes {
+		if strings.HasSuffix(base, " - "+SanitizeFilename(ep.Title)) {
+			return ep, true
+		}
+	}
+
+	if m := numberedFile.FindStringSubmatch(base); m != nil {
+		index, _ := strconv.Atoi(m[1])
+		for _, ep := range episodes {
+			if ep.Index == index {
+				return ep, true
+			}
+		}
+	}
+	return Episode{}, false
+}
+
+// audioMIMEExtensions maps enclosure MIME types to file extensions
+var audioMIMEExtensions = map[string]string{
+	"audio/mpeg":   ".mp3",
+	"audio/mp3":    ".mp3",
+	"audio/mpeg3":  ".mp3",
+	"audio/x-mp3":  ".mp3",
+	"audio/x-mpeg": ".mp3",
+	"audio/mp4":    ".m4a",
+	"audio/m4a":    ".m4a",
+	"audio/x-m4a":  ".m4a",
+	"audio/aac":    ".aac",
+	"audio/x-aac":  ".aac",
+	"audio/ogg":    ".ogg",
+	"audio/opus":   ".opus",
+	"audio/wav":    ".wav",
+	"audio/x-wav":  ".wav",
+	"audio/flac":   ".flac",
+	"audio/x-flac": ".flac",
+}
+
+// ExtensionOverrides maps MIME types to the extension to use for them,
+// ahead of the built-in mapping, for feeds that mislabel their enclosures
+var ExtensionOverrides map[string]string
+
+// extensionPattern matches an extension given to --audio-ext-map
+var extensionPattern = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
+// ParseExtensionMap parses an --audio-ext-map value: comma-separated
+// type=extension pairs such as audio/x-m4a=.m4a
+func ParseExtensionMap(s string) (map[string]string, error) {
+	overrides := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		mimeType, ext, ok := strings.Cut(pair, "=")
+		mimeType = normalizeMIMEType(mimeType)
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if !ok || !strings.Contains(mimeType, "/") || !extensionPattern.MatchString(ext) {
+			return nil, fmt.Errorf("invalid mapping %q (expected type=.ext, e.g. audio/x-m4a=.m4a)", strings.TrimSpace(pair))
+		}
+		overrides[mimeType] = ext
+	}
+	return overrides, nil
+}
+
+// normalizeMIMEType lowercases a MIME type and drops its parameters
+func normalizeMIMEType(mimeType string) string {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
+
+// AudioExtension derives a file extension for an enclosure from its MIME
+// type, checking ExtensionOverrides first, then falling back to the
+// extension of the media URL behind any tracking prefixes and finally to
+// .mp3
+func AudioExtension(mimeType, audioURL string) string {
+	mimeType = normalizeMIMEType(mimeType)
+	if ext, ok := ExtensionOverrides[mimeType]; ok {
+		return ext
+	}
+	if ext, ok := audioMIMEExtensions[mimeType]; ok {
+		return ext
+	}
+
+	if u, err := url.Parse(MediaURL(audioURL)); err == nil {
+		ext := strings.ToLower(path.Ext(u.Path))
+		for _, known := range audioMIMEExtensions {
+			if ext == known {
+				return ext
+			}
+		}
+	}
+
+	return ".mp3"
+}
+
+// IsMP3 reports whether a file name has an .mp3 extension
+func IsMP3(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".mp3")
+}
+
+// DefaultMaxFilenameLength is the default limit, in characters, on the
+// title part of a file name
+const DefaultMaxFilenameLength = 100
+
+// MaxFilenameLength limits the title part of file names, in characters
+// (--max-filename-length)
+var MaxFilenameLength = DefaultMaxFilenameLength
+
+// maxFilenameBytes limits the title part of a file name in bytes, keeping
+// multi-byte titles, with the number prefix and extension, under the
+// maxNameBytes most filesystems allow
+const (
+	maxFilenameBytes = 200
+	maxNameBytes     = 255
+)
+
+// maxPathLength is the longest full path the system accepts
+func maxPathLength() int {
+	switch runtime.GOOS {
+	case "windows":
+		return 259 // MAX_PATH, less the terminating NUL
+	case "darwin":
+		return 1023
+	}
+	return 4095
+}
+
+// SanitizeFilename strips characters that are invalid in file names and
+// limits the length to MaxFilenameLength characters, cutting at a word
+// boundary where it can
+func SanitizeFilename(name string) string {
+	// Remove invalid characters
+	re := regexp.MustCompile(`[<>:"/\\|?*]`)
+	name = re.ReplaceAllString(name, "")
+	name = strings.TrimSpace(name)
+
+	name = shorten(name, MaxFilenameLength, maxFilenameBytes)
+	if name == "" {
+		return "episode"
+	}
+	return name
+}
+
+// shorten cuts name to at most maxRunes characters and maxBytes bytes. It
+// cuts between characters so multi-byte titles stay valid UTF-8, and at the
+// last space within the limit unless that would drop more than half, so
+// titles don't end mid-word.
+func shorten(name string, maxRunes, maxBytes int) string {
+	runes := []rune(name)
+	if len(runes) <= maxRunes && len(name) <= maxBytes {
+		return name
+	}
+
+	cut := min(len(runes), max(maxRunes, 0))
+	for cut > 0 && len(string(runes[:cut])) > maxBytes {
+		cut--
+	}
+	if cut < len(runes) && !unicode.IsSpace(runes[cut]) {
+		for i := cut - 1; i > cut/2; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("-,.;:", r)
+	})
+}
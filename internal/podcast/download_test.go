@@ -0,0 +1,77 @@
+package podcast
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bogem/id3v2"
+)
+
+// TestAddID3TagsRoundTrip tags an MP3 that already carries a publisher's
+// UTF-8 frame with non-ASCII text, under each ID3 version, and checks the
+// text reads back unchanged
+func TestAddID3TagsRoundTrip(t *testing.T) {
+	const (
+		title     = "Épisode 12 — Ünïcode: 日本語のタイトル"
+		album     = "Café Società ☕"
+		notes     = "Notes de l'épisode: naïve façade, Ελληνικά"
+		publisher = "Éditions Ōtsuka"
+	)
+	ep := Episode{Index: 12, Title: title, Description: notes, Duration: "1:02:03"}
+	info := PodcastInfo{Name: album, Artist: "Zoë", Language: "fr"}
+
+	for _, version := range []byte{3, 4} {
+		path := filepath.Join(t.TempDir(), "episode.mp3")
+		// A few MPEG frame headers stand in for audio
+		if err := os.WriteFile(path, bytes.Repeat([]byte{0xFF, 0xFB, 0x90, 0x64}, 256), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		// The publisher's own tag, in UTF-8, which ID3v2.3 doesn't allow
+		tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag.SetVersion(4)
+		tag.AddTextFrame(tag.CommonID("Publisher"), id3v2.EncodingUTF8, publisher)
+		if err := tag.Save(); err != nil {
+			t.Fatal(err)
+		}
+		tag.Close()
+
+		if err := AddID3Tags(path, ep, info, TagOptions{Version: version}); err != nil {
+			t.Fatalf("v2.%d: AddID3Tags: %v", version, err)
+		}
+
+		tag, err = id3v2.Open(path, id3v2.Options{Parse: true})
+		if err != nil {
+			t.Fatalf("v2.%d: reopening: %v", version, err)
+		}
+		if got := tag.Version(); got != version {
+			t.Errorf("v2.%d: tag version is 2.%d", version, got)
+		}
+		if got := tag.Title(); got != title {
+			t.Errorf("v2.%d: title = %q, want %q", version, got, title)
+		}
+		if got := tag.Album(); got != album {
+			t.Errorf("v2.%d: album = %q, want %q", version, got, album)
+		}
+		pub := tag.GetTextFrame(tag.CommonID("Publisher"))
+		if pub.Text != publisher {
+			t.Errorf("v2.%d: publisher = %q, want %q", version, pub.Text, publisher)
+		}
+		// Players reading a v2.3 tag don't expect UTF-8
+		if want := encoding(version); !pub.Encoding.Equals(want) {
+			t.Errorf("v2.%d: publisher frame encoded as %v, want %v", version, pub.Encoding, want)
+		}
+		comments := tag.GetFrames(tag.CommonID("Comments"))
+		if len(comments) != 1 {
+			t.Errorf("v2.%d: %d comment frames, want 1", version, len(comments))
+		} else if got := comments[0].(id3v2.CommentFrame).Text; got != notes {
+			t.Errorf("v2.%d: comment = %q, want %q", version, got, notes)
+		}
+		tag.Close()
+	}
+}
@@ -0,0 +1,45 @@
+package podcast
+
+import "errors"
+
+// Error kinds returned by this package, to be checked with errors.Is
+var (
+	// ErrNotFound means no podcast matched the ID, search or feed
+	ErrNotFound = errors.New("no podcast found")
+
+	// ErrNoFeed means the directory lists the podcast without an RSS feed
+	ErrNoFeed = errors.New("no RSS feed URL found for this podcast")
+
+	// ErrNoEpisodes means the feed has no episodes with audio to download
+	ErrNoEpisodes = errors.New("no downloadable episodes found")
+
+	// ErrTooSmall means a download finished but is too small to be an
+	// episode, typically an error page served with a 200 status
+	ErrTooSmall = errors.New("download too small to be audio")
+
+	// ErrStalled means a download received no data for its stall timeout,
+	// on every retry
+	ErrStalled = errors.New("download stalled")
+
+	// ErrNetwork means a server couldn't be reached, as opposed to it
+	// answering with an error
+	ErrNetwork = errors.New("network error")
+)
+
+// networkError marks a transport failure as ErrNetwork while keeping the
+// underlying error's message and identity
+type networkError struct {
+	err error
+}
+
+func (e *networkError) Error() string { return e.err.Error() }
+
+func (e *networkError) Unwrap() []error { return []error{e.err, ErrNetwork} }
+
+// asNetworkError wraps a transport failure from an HTTP client
+func asNetworkError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &networkError{err: err}
+}
@@ -0,0 +1,682 @@
+package podcast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+	"golang.org/x/net/html/charset"
+)
+
+// LoadByID looks up a podcast by Apple ID and parses its feed
+func LoadByID(podcastID string) (PodcastInfo, []Episode, error) {
+	// Remove "id" prefix if present
+	podcastID = strings.TrimPrefix(strings.ToLower(podcastID), "id")
+
+	// Fetch from iTunes API
+	url := fmt.Sprintf("https://itunes.apple.com/lookup?id=%s&entity=podcast", podcastID) + appleCountryParam()
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return PodcastInfo{}, nil, fmt.Errorf("failed to lookup podcast: %w", err)
+	}
+	resp, err := doWith(httpClient, req)
+	if err != nil {
+		return PodcastInfo{}, nil, fmt.Errorf("failed to lookup podcast: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result iTunesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return PodcastInfo{}, nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	// The lookup can return episodes and other entities besides the show;
+	// take the first show, preferring one that has a feed
+	show, episode := -1, -1
+	for i, r := range result.Results {
+		switch {
+		case r.Kind == "podcast-episode" || r.WrapperType == "podcastEpisode":
+			if episode < 0 {
+				episode = i
+			}
+		case r.CollectionName == "":
+		case r.FeedURL != "":
+			if show < 0 || result.Results[show].FeedURL == "" {
+				show = i
+			}
+		case show < 0:
+			show = i
+		}
+	}
+	if show < 0 {
+		if episode >= 0 {
+			e := result.Results[episode]
+			return PodcastInfo{}, nil, fmt.Errorf("%w: ID %s is an episode of %q, not a show; use the show's ID %d instead", ErrNotFound, podcastID, e.CollectionName, e.CollectionID)
+		}
+		return PodcastInfo{}, nil, fmt.Errorf("%w with ID: %s", ErrNotFound, podcastID)
+	}
+
+	r := result.Results[show]
+	info := PodcastInfo{
+		Name:       r.CollectionName,
+		Artist:     r.ArtistName,
+		FeedURL:    r.FeedURL,
+		ArtworkURL: appleArtworkURL(r.ArtworkURL100, r.ArtworkURL600),
+	}
+
+	if info.FeedURL == "" {
+		return PodcastInfo{}, nil, ErrNoFeed
+	}
+
+	return loadFeed(info, Validators{})
+}
+
+// LoadFeed parses an RSS feed URL or file into podcast info and episodes. Any of
+// name, artist and artworkURL left empty is taken from the feed.
+func LoadFeed(feedURL, name, artist, artworkURL string) (PodcastInfo, []Episode, error) {
+	info := PodcastInfo{
+		Name:       name,
+		Artist:     artist,
+		FeedURL:    feedURL,
+		ArtworkURL: artworkURL,
+	}
+	return loadFeed(info, Validators{})
+}
+
+// LoadFeedIfModified is LoadFeed for a feed loaded before, which the server
+// last served with since. It returns ErrNotModified, without downloading the
+// feed again, when the server says it hasn't changed.
+func LoadFeedIfModified(feedURL string, since Validators) (PodcastInfo, []Episode, error) {
+	return loadFeed(PodcastInfo{FeedURL: feedURL}, since)
+}
+
+// loadFeed fetches and parses info's feed, filling in info from it
+func loadFeed(info PodcastInfo, since Validators) (PodcastInfo, []Episode, error) {
+	doc, err := fetchFeed(info.FeedURL, since)
+	if err != nil {
+		return PodcastInfo{}, nil, err
+	}
+	feed, err := doc.Parse()
+	if err != nil {
+		return PodcastInfo{}, nil, err
+	}
+	info.FeedMoved(doc.MovedTo)
+	info.Validators = doc.Validators
+	info.Feed = doc.Data
+	return FromFeed(feed, info)
+}
+
+// LoadResult loads the podcast behind a search result
+func LoadResult(r SearchResult) (PodcastInfo, []Episode, error) {
+	if r.Source == ProviderPodcastIndex {
+		return LoadFeed(r.FeedURL, r.Name, r.Artist, r.ArtworkURL)
+	}
+	return LoadByID(r.ID)
+}
+
+// Resolve loads a podcast from a feed URL or file, an Apple ID, or the top
+// search match for anything else. A non-empty author restricts the search matches
+// to podcasts by that author.
+func Resolve(input string, provider SearchProvider, author string) (PodcastInfo, []Episode, error) {
+	switch {
+	case IsFeedURL(input), IsFeedFile(input):
+		return LoadFeed(input, "", "", "")
+	case IsID(input):
+		return LoadByID(input)
+	}
+
+	results, err := Search(input, provider)
+	if err != nil {
+		return PodcastInfo{}, nil, err
+	}
+	if author != "" {
+		results = FilterByAuthor(results, author)
+	}
+	if len(results) == 0 {
+		return PodcastInfo{}, nil, fmt.Errorf("%w for: %s", ErrNotFound, input)
+	}
+	return LoadResult(results[0])
+}
+
+// ParseFeed fetches and parses an RSS feed. If every redirect on the way
+// was permanent (301 or 308), movedTo is the feed's new URL.
+func ParseFeed(feedURL string) (feed *gofeed.Feed, movedTo string, err error) {
+	doc, err := FetchFeed(feedURL)
+	if err != nil {
+		return nil, "", err
+	}
+	feed, err = doc.Parse()
+	if err != nil {
+		return nil, "", err
+	}
+	return feed, doc.MovedTo, nil
+}
+
+// FeedTimeout bounds fetching a feed, from the request to the last byte,
+// so a connection that trickles data can't hang a load; 0 waits indefinitely
+var FeedTimeout time.Duration
+
+// FeedDocument is a feed as served, before parsing
+type FeedDocument struct {
+	Data        []byte
+	ContentType string // the Content-Type header
+	MovedTo     string // new feed URL when every redirect was permanent
+	Validators  Validators
+}
+
+// Validators are the ETag and Last-Modified headers a feed was served
+// with. Sent back on the next fetch, they let the server answer 304 Not
+// Modified instead of sending the feed again when it hasn't changed.
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// IsZero reports whether the server sent neither header
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// ErrNotModified means a feed hasn't changed since it was last loaded
+var ErrNotModified = errors.New("feed not modified")
+
+// FetchFeed downloads a feed document, or reads it from disk when feedURL
+// is a local feed file
+func FetchFeed(feedURL string) (FeedDocument, error) {
+	return fetchFeed(feedURL, Validators{})
+}
+
+// fetchFeed is FetchFeed, asking the server to answer 304 Not Modified if
+// the feed still matches since, in which case it returns ErrNotModified
+func fetchFeed(feedURL string, since Validators) (FeedDocument, error) {
+	if IsFeedFile(feedURL) {
+		data, err := os.ReadFile(feedURL)
+		if err != nil {
+			return FeedDocument{}, fmt.Errorf("failed to read feed file: %w", err)
+		}
+		return FeedDocument{Data: data}, nil
+	}
+
+	permanent := true
+	client := &http.Client{
+		Transport: httpClient.Transport,
+		Jar:       sessionCookies, // the enclosures may need the feed's session cookie
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			code := req.Response.StatusCode
+			permanent = permanent && (code == http.StatusMovedPermanently || code == http.StatusPermanentRedirect)
+			return nil
+		},
+	}
+
+	ctx := context.Background()
+	if FeedTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, FeedTimeout)
+		defer cancel()
+	}
+	req, err := newFeedRequest(ctx, feedURL)
+	if err != nil {
+		return FeedDocument{}, fmt.Errorf("failed to fetch RSS feed: %w", err)
+	}
+	if since.ETag != "" {
+		req.Header.Set("If-None-Match", since.ETag)
+	}
+	if since.LastModified != "" {
+		req.Header.Set("If-Modified-Since", since.LastModified)
+	}
+	resp, err := doWith(client, req)
+	if err != nil {
+		return FeedDocument{}, fmt.Errorf("failed to fetch RSS feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && !since.IsZero() {
+		return FeedDocument{}, ErrNotModified
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return FeedDocument{}, fmt.Errorf("failed to fetch RSS feed: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return FeedDocument{}, fmt.Errorf("failed to fetch RSS feed: %w", asNetworkError(err))
+	}
+
+	doc := FeedDocument{
+		Data:        data,
+		ContentType: resp.Header.Get("Content-Type"),
+		Validators:  Validators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")},
+	}
+	if finalURL := resp.Request.URL.String(); permanent && finalURL != feedURL {
+		doc.MovedTo = finalURL
+	}
+	return doc, nil
+}
+
+// xmlEncodingDecl matches an XML prolog that declares its encoding
+var xmlEncodingDecl = regexp.MustCompile(`^<\?xml[^>]*\bencoding\s*=`)
+
+// Parse parses the document. It tolerates what trips up gofeed on some
+// hosts: a UTF-8 byte order mark or whitespace before the prolog, and a
+// charset given only in the Content-Type header. A charset declared in the
+// prolog is honored by gofeed itself.
+func (d FeedDocument) Parse() (*gofeed.Feed, error) {
+	data := bytes.TrimPrefix(d.Data, []byte("\xEF\xBB\xBF"))
+	data = bytes.TrimLeft(data, " \t\r\n")
+
+	var r io.Reader = bytes.NewReader(data)
+	if !xmlEncodingDecl.Match(data) {
+		if _, params, err := mime.ParseMediaType(d.ContentType); err == nil {
+			if label := params["charset"]; label != "" && !strings.EqualFold(label, "utf-8") {
+				if converted, err := charset.NewReaderLabel(label, r); err == nil {
+					r = converted
+				}
+			}
+		}
+	}
+
+	fp := gofeed.NewParser()
+	feed, err := fp.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RSS feed: %w", err)
+	}
+	return feed, nil
+}
+
+// FeedFilename is the name the raw feed is saved under in the podcast folder
+const FeedFilename = "feed.xml"
+
+// SaveFeed writes the feed document a podcast was loaded from, as served,
+// to path
+func SaveFeed(info PodcastInfo, path string) error {
+	if len(info.Feed) == 0 {
+		return errors.New("failed to save feed: the feed wasn't kept when it was loaded")
+	}
+	if err := os.WriteFile(path, info.Feed, 0644); err != nil {
+		return fmt.Errorf("failed to save feed: %w", err)
+	}
+	return nil
+}
+
+// FromFeed extracts podcast info and episodes from an already parsed feed,
+// filling in any metadata the caller didn't provide
+func FromFeed(feed *gofeed.Feed, info PodcastInfo) (PodcastInfo, []Episode, error) {
+	// Use feed title/author if not provided
+	if info.Name == "" && feed.Title != "" {
+		info.Name = feed.Title
+	}
+	if info.Artist == "" && feed.Author != nil {
+		info.Artist = feed.Author.Name
+	}
+	if info.ArtworkURL == "" && feed.Image != nil {
+		info.ArtworkURL = feed.Image.URL
+	}
+	info.Blocked = FeedBlocked(feed)
+	feedMetadata(feed, &info)
+
+	episodes := ParseEpisodes(feed)
+	if len(episodes) == 0 {
+		return PodcastInfo{}, nil, ErrNoEpisodes
+	}
+
+	return info, episodes, nil
+}
+
+// AnnouncedFeedURL returns the URL a feed says it moved to with
+// itunes:new-feed-url, or "" when it names none other than feedURL. A moved
+// feed may keep serving its old copy, so the tag is the only sign of the move.
+func AnnouncedFeedURL(feed *gofeed.Feed, feedURL string) string {
+	if feed.ITunesExt == nil {
+		return ""
+	}
+	u := strings.TrimSpace(feed.ITunesExt.NewFeedURL)
+	if u == "" || normalizeFeedURL(u) == normalizeFeedURL(feedURL) {
+		return ""
+	}
+	return u
+}
+
+// feedMetadata copies the show-level metadata of feed into info
+func feedMetadata(feed *gofeed.Feed, info *PodcastInfo) {
+	description := feed.Description
+	if strings.TrimSpace(description) == "" && feed.ITunesExt != nil {
+		description = feed.ITunesExt.Summary
+	}
+	info.Description = cleanHTML(description)
+	info.Language = strings.TrimSpace(feed.Language)
+	if feed.UpdatedParsed != nil {
+		info.LastBuild = *feed.UpdatedParsed
+	}
+
+	info.NewFeedURL = AnnouncedFeedURL(feed, info.FeedURL)
+	info.Funding = ParseFunding(feed)
+	info.Value = ParseValue(feed)
+	info.Trailers = ParseTrailers(feed)
+
+	info.Categories = nil
+	if feed.ITunesExt != nil {
+		for _, c := range feed.ITunesExt.Categories {
+			if c == nil || c.Text == "" {
+				continue
+			}
+			name := c.Text
+			if c.Subcategory != nil && c.Subcategory.Text != "" {
+				name += " > " + c.Subcategory.Text
+			}
+			info.Categories = append(info.Categories, name)
+		}
+	}
+	if len(info.Categories) == 0 {
+		for _, c := range feed.Categories {
+			if c = strings.TrimSpace(c); c != "" {
+				info.Categories = append(info.Categories, c)
+			}
+		}
+	}
+}
+
+// ParseEpisodes extracts the downloadable episodes from a parsed feed
+func ParseEpisodes(feed *gofeed.Feed) []Episode {
+	var episodes []Episode
+	// Channel-level people apply to every episode that names none itself
+	feedPeople := parsePeople(feed.Extensions)
+	feedExplicit := feed.ITunesExt != nil && isExplicit(feed.ITunesExt.Explicit)
+	for i, item := range feed.Items {
+		audioURL, audioType := "", ""
+		var length int64
+
+		// Find audio enclosure
+		for _, enc := range item.Enclosures {
+			if strings.Contains(enc.Type, "audio") || strings.HasSuffix(enc.URL, ".mp3") {
+				audioURL = enc.URL
+				audioType = enc.Type
+				length = enclosureLength(enc.Length)
+				break
+			}
+		}
+
+		if audioURL == "" {
+			continue
+		}
+
+		var pubDate time.Time
+		if item.PublishedParsed != nil {
+			pubDate = *item.PublishedParsed
+		} else if t, ok := parseLooseDate(item.Published); ok {
+			pubDate = t
+		}
+
+		// Prefer the full show notes (content:encoded) over the summary
+		description := item.Description
+		if strings.TrimSpace(item.Content) != "" {
+			description = item.Content
+		}
+
+		duration, author, blocked, explicit := "", "", false, feedExplicit
+		if item.ITunesExt != nil {
+			duration = item.ITunesExt.Duration
+			author = strings.TrimSpace(item.ITunesExt.Author)
+			blocked = isBlocked(item.ITunesExt.Block)
+			if strings.TrimSpace(item.ITunesExt.Explicit) != "" {
+				explicit = isExplicit(item.ITunesExt.Explicit)
+			}
+		}
+
+		people := parsePeople(item.Extensions)
+		if len(people) == 0 {
+			people = feedPeople
+		}
+
+		episodes = append(episodes, Episode{
+			Index:        i + 1,
+			GUID:         strings.TrimSpace(item.GUID),
+			Title:        item.Title,
+			Description:  description,
+			Author:       author,
+			AudioURL:     audioURL,
+			AudioType:    audioType,
+			ExpectedSize: length,
+			PubDate:      pubDate,
+			Duration:     duration,
+			Blocked:      blocked,
+			Explicit:     explicit,
+			People:       people,
+		})
+	}
+	return episodes
+}
+
+// looseDateLayouts are tried on a pubDate gofeed can't parse, once
+// parseLooseDate has taken out its weekday, ordinals and comments
+var looseDateLayouts = []string{
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 MST-0700",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04 MST",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006 3:04 PM",
+	"2 Jan 2006",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04:05 MST",
+	"2 January 2006 15:04:05",
+	"2 January 2006",
+	"Jan 2 2006 15:04:05 -0700",
+	"Jan 2 2006 15:04:05 MST",
+	"Jan 2 2006 15:04:05",
+	"Jan 2 2006 3:04 PM",
+	"Jan 2 2006",
+	"January 2 2006 15:04:05 -0700",
+	"January 2 2006 15:04:05",
+	"January 2 2006 3:04 PM",
+	"January 2 2006",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+}
+
+var (
+	// dateWeekday matches a leading day name, which feeds abbreviate
+	// every which way ("Tues", "Thurs") and sometimes get wrong
+	dateWeekday = regexp.MustCompile(`(?i)^(mon|tue|wed|thu|fri|sat|sun)[a-z]*\.?,?\s+`)
+	// dateOrdinal matches the suffix of a day written "5th"
+	dateOrdinal = regexp.MustCompile(`(?i)\b(\d{1,2})(st|nd|rd|th)\b`)
+	// dateComment matches a trailing "(UTC)" or similar
+	dateComment = regexp.MustCompile(`\s*\([^)]*\)$`)
+	// dateSept matches the four-letter September abbreviation Go doesn't know
+	dateSept = regexp.MustCompile(`(?i)\bsept\b`)
+)
+
+// parseLooseDate recovers a publication date from the slightly off forms
+// some feeds use, which gofeed gives up on: a misspelt or missing weekday,
+// "Sept", ordinals, commas in odd places or a trailing comment
+func parseLooseDate(s string) (time.Time, bool) {
+	s = strings.Join(strings.Fields(s), " ")
+	s = dateComment.ReplaceAllString(s, "")
+	s = dateWeekday.ReplaceAllString(s, "")
+	s = dateOrdinal.ReplaceAllString(s, "$1")
+	s = strings.ReplaceAll(s, ",", " ")
+	s = strings.Join(strings.Fields(s), " ")
+	s = dateSept.ReplaceAllString(s, "Sep")
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range looseDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// enclosureLength parses an enclosure's length attribute. Feeds that don't
+// know the size often put 0 or 1 there, so implausibly small values count
+// as unknown.
+func enclosureLength(value string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || n < 1024 {
+		return 0
+	}
+	return n
+}
+
+// parsePeople reads the podcast:person elements of a channel or item
+func parsePeople(extensions ext.Extensions) []Person {
+	var people []Person
+	for _, e := range extensions["podcast"]["person"] {
+		name := strings.TrimSpace(e.Value)
+		if name == "" {
+			continue
+		}
+		role := strings.ToLower(strings.TrimSpace(e.Attrs["role"]))
+		if role == "" {
+			role = "host"
+		}
+		people = append(people, Person{Name: name, Role: role})
+	}
+	return people
+}
+
+// ParseFunding reads the podcast:funding links of a feed
+func ParseFunding(feed *gofeed.Feed) []Funding {
+	var funding []Funding
+	for _, e := range feed.Extensions["podcast"]["funding"] {
+		u := strings.TrimSpace(e.Attrs["url"])
+		if u == "" {
+			continue
+		}
+		funding = append(funding, Funding{URL: u, Text: strings.TrimSpace(e.Value)})
+	}
+	return funding
+}
+
+// ParseValue reads the channel's podcast:value block, or returns nil when
+// the feed has none
+func ParseValue(feed *gofeed.Feed) *ValueBlock {
+	values := feed.Extensions["podcast"]["value"]
+	if len(values) == 0 {
+		return nil
+	}
+	e := values[0]
+	value := &ValueBlock{
+		Type:   strings.TrimSpace(e.Attrs["type"]),
+		Method: strings.TrimSpace(e.Attrs["method"]),
+	}
+	for _, r := range e.Children["valueRecipient"] {
+		if name := strings.TrimSpace(r.Attrs["name"]); name != "" {
+			value.Recipients = append(value.Recipients, name)
+		}
+	}
+	return value
+}
+
+// trailerDateLayouts are the RFC 2822 forms podcast:trailer pubdate
+// attributes come in
+var trailerDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+}
+
+// ParseTrailers reads the channel's podcast:trailer clips as episodes, so
+// they download like any other. A trailer without a title is named
+// "Trailer".
+func ParseTrailers(feed *gofeed.Feed) []Episode {
+	var trailers []Episode
+	for _, e := range feed.Extensions["podcast"]["trailer"] {
+		u := strings.TrimSpace(e.Attrs["url"])
+		if u == "" {
+			continue
+		}
+		title := strings.TrimSpace(e.Value)
+		if title == "" {
+			title = "Trailer"
+		}
+		ep := Episode{
+			Index:        len(trailers) + 1,
+			Title:        title,
+			AudioURL:     u,
+			AudioType:    strings.TrimSpace(e.Attrs["type"]),
+			ExpectedSize: enclosureLength(e.Attrs["length"]),
+		}
+		for _, layout := range trailerDateLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(e.Attrs["pubdate"])); err == nil {
+				ep.PubDate = t
+				break
+			}
+		}
+		trailers = append(trailers, ep)
+	}
+	return trailers
+}
+
+// FeedBlocked reports whether the publisher set itunes:block on the feed
+func FeedBlocked(feed *gofeed.Feed) bool {
+	return feed.ITunesExt != nil && isBlocked(feed.ITunesExt.Block)
+}
+
+// isBlocked reports whether an itunes:block value asks for the feed or
+// episode not to be listed; only "Yes" does
+func isBlocked(value string) bool {
+	return strings.EqualFold(strings.TrimSpace(value), "yes")
+}
+
+// isExplicit reads an itunes:explicit value; older feeds say "yes" or
+// "explicit" where newer ones say "true"
+func isExplicit(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "true", "explicit":
+		return true
+	}
+	return false
+}
+
+var (
+	htmlBlockEnd = regexp.MustCompile(`(?i)</(p|div|h[1-6]|ul|ol|blockquote)>`)
+	htmlLineEnd  = regexp.MustCompile(`(?i)<br\s*/?>|</li>`)
+	htmlTag      = regexp.MustCompile(`<[^>]*>`)
+	blankLines   = regexp.MustCompile(`\n{3,}`)
+)
+
+// cleanHTML turns feed show notes into plain text, keeping paragraph and
+// line breaks
+func cleanHTML(s string) string {
+	s = htmlBlockEnd.ReplaceAllString(s, "\n\n")
+	s = htmlLineEnd.ReplaceAllString(s, "\n")
+	s = htmlTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	s = strings.Join(lines, "\n")
+	s = blankLines.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,75 @@
+package podcast
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LanguageTagDescription names the TXXX frame holding the feed's language
+// code as the feed gives it, e.g. "en-US"
+const LanguageTagDescription = "LANGUAGE"
+
+// languageCode matches a language code in either style, "en" or "en-US"
+var languageCode = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// normalizeLanguage lowercases a language code and accepts en_US for en-US
+func normalizeLanguage(code string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(code)), "_", "-")
+}
+
+// ParseLanguages validates a --language value: one or more comma-separated
+// codes such as en or pt-BR
+func ParseLanguages(s string) ([]string, error) {
+	var codes []string
+	for _, part := range strings.Split(s, ",") {
+		code := normalizeLanguage(part)
+		if code == "" {
+			continue
+		}
+		if !languageCode.MatchString(code) {
+			return nil, fmt.Errorf("invalid language code %q (expected e.g. en or en-US)", strings.TrimSpace(part))
+		}
+		codes = append(codes, code)
+	}
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("no language code given")
+	}
+	return codes, nil
+}
+
+// MatchesLanguage reports whether a feed's language is one of wanted. A
+// plain code matches every region of the language, so en accepts en-US and
+// en-GB, while en-US rejects en-GB but accepts a feed that names no region.
+func MatchesLanguage(language string, wanted []string) bool {
+	lang, region, _ := strings.Cut(normalizeLanguage(language), "-")
+	for _, w := range wanted {
+		wantLang, wantRegion, _ := strings.Cut(w, "-")
+		if lang == wantLang && (wantRegion == "" || region == "" || region == wantRegion) {
+			return true
+		}
+	}
+	return false
+}
+
+// iso639_2 maps the two-letter codes feeds use to the three-letter codes
+// the ID3 TLAN frame expects, for the languages podcasts are common in
+var iso639_2 = map[string]string{
+	"ar": "ara", "ca": "cat", "cs": "ces", "da": "dan", "de": "deu",
+	"el": "ell", "en": "eng", "es": "spa", "fa": "fas", "fi": "fin",
+	"fr": "fra", "he": "heb", "hi": "hin", "hu": "hun", "id": "ind",
+	"it": "ita", "ja": "jpn", "ko": "kor", "nb": "nob", "nl": "nld",
+	"no": "nor", "pl": "pol", "pt": "por", "ro": "ron", "ru": "rus",
+	"sv": "swe", "th": "tha", "tr": "tur", "uk": "ukr", "vi": "vie",
+	"zh": "zho",
+}
+
+// tagLanguage returns the ISO 639-2 code for a feed's language, or "" when
+// it isn't known
+func tagLanguage(language string) string {
+	lang, _, _ := strings.Cut(normalizeLanguage(language), "-")
+	if len(lang) == 3 {
+		return lang
+	}
+	return iso639_2[lang]
+}
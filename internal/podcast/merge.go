@@ -0,0 +1,181 @@
+package podcast
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MergeListings adds the episodes of other directory listings of the same
+// show to episodes. Apple and Podcast Index sometimes list a show under
+// different feeds, one of them stale or truncated, so the union is the most
+// complete list. Listings match on title and author; their feed URLs end up
+// in info.MergedFrom. Merging is best-effort: a failed search or feed leaves
+// the episodes as they were.
+func MergeListings(info PodcastInfo, episodes []Episode) (PodcastInfo, []Episode) {
+	var results []SearchResult
+	var err error
+	if HasPodcastIndexCredentials() {
+		results, err = SearchBoth(info.Name)
+	} else {
+		results, err = SearchApple(info.Name)
+	}
+	if err != nil {
+		return info, episodes
+	}
+
+	seen := map[string]bool{normalizeFeedURL(info.FeedURL): true}
+	if info.MovedFrom != "" {
+		seen[normalizeFeedURL(info.MovedFrom)] = true
+	}
+	for _, r := range results {
+		if seen[normalizeFeedURL(r.FeedURL)] || !SameShow(info, r) {
+			continue
+		}
+		seen[normalizeFeedURL(r.FeedURL)] = true
+
+		_, other, err := LoadFeed(r.FeedURL, r.Name, r.Artist, r.ArtworkURL)
+		if err != nil {
+			continue
+		}
+		before := len(episodes)
+		episodes = MergeEpisodes(episodes, other)
+		if len(episodes) > before {
+			info.MergedFrom = append(info.MergedFrom, r.FeedURL)
+		}
+	}
+
+	if len(info.MergedFrom) > 0 {
+		// Interleave the additions by date and number the whole list afresh
+		SortEpisodes(episodes, SortDate, false)
+	}
+	return info, episodes
+}
+
+// MergeEpisodes appends the episodes of extra that aren't in episodes,
+// matching on GUID, then audio URL, then title and publication day
+func MergeEpisodes(episodes, extra []Episode) []Episode {
+	seen := make(map[string]bool)
+	for _, ep := range episodes {
+		for _, key := range episodeKeys(ep) {
+			seen[key] = true
+		}
+	}
+
+	for _, ep := range extra {
+		keys := episodeKeys(ep)
+		duplicate := false
+		for _, key := range keys {
+			duplicate = duplicate || seen[key]
+		}
+		if duplicate {
+			continue
+		}
+		for _, key := range keys {
+			seen[key] = true
+		}
+		episodes = append(episodes, ep)
+	}
+	return episodes
+}
+
+// CarrySelection selects the episodes that match one selected in before,
+// the same show's episodes as loaded from another listing or feed, and
+// returns how many it selected. Episodes match as in MergeEpisodes.
+func CarrySelection(episodes, before []Episode) int {
+	selected := make(map[string]bool)
+	for _, ep := range before {
+		if !ep.Selected {
+			continue
+		}
+		for _, key := range episodeKeys(ep) {
+			selected[key] = true
+		}
+	}
+	if len(selected) == 0 {
+		return 0
+	}
+
+	carried := 0
+	for i := range episodes {
+		for _, key := range episodeKeys(episodes[i]) {
+			if selected[key] {
+				episodes[i].Selected = true
+				carried++
+				break
+			}
+		}
+	}
+	return carried
+}
+
+// episodeKeys are the identities an episode is matched on across feeds
+func episodeKeys(ep Episode) []string {
+	var keys []string
+	if ep.GUID != "" {
+		keys = append(keys, "guid:"+ep.GUID)
+	}
+	if ep.AudioURL != "" {
+		keys = append(keys, "url:"+ep.AudioURL)
+	}
+	title := strings.Join(strings.Fields(strings.ToLower(ep.Title)), " ")
+	keys = append(keys, "title:"+title+"|"+ep.PubDate.UTC().Format("2006-01-02"))
+	return keys
+}
+
+// SameShow reports whether a search result looks like another listing of
+// the show in info: the same title, and the same author when both give one
+func SameShow(info PodcastInfo, r SearchResult) bool {
+	if !strings.EqualFold(strings.TrimSpace(info.Name), strings.TrimSpace(r.Name)) {
+		return false
+	}
+	a, b := strings.ToLower(info.Artist), strings.ToLower(r.Artist)
+	return a == "" || b == "" || strings.Contains(a, b) || strings.Contains(b, a)
+}
+
+// DedupEpisodes collapses episodes a feed lists more than once, as feeds
+// that re-release old episodes under new GUIDs do: those with the same
+// audio URL, or the same title and duration. The newest of each is kept in
+// its place, with its number, and how many were removed is returned.
+func DedupEpisodes(episodes []Episode) ([]Episode, int) {
+	order := make([]int, len(episodes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return episodes[order[a]].PubDate.After(episodes[order[b]].PubDate)
+	})
+
+	seen := make(map[string]bool)
+	keep := make([]bool, len(episodes))
+	for _, i := range order {
+		keys := duplicateKeys(episodes[i])
+		duplicate := false
+		for _, key := range keys {
+			duplicate = duplicate || seen[key]
+			seen[key] = true
+		}
+		keep[i] = !duplicate
+	}
+
+	kept := episodes[:0:0]
+	for i, ep := range episodes {
+		if keep[i] {
+			kept = append(kept, ep)
+		}
+	}
+	return kept, len(episodes) - len(kept)
+}
+
+// duplicateKeys are the identities DedupEpisodes matches episodes on
+func duplicateKeys(ep Episode) []string {
+	var keys []string
+	if ep.AudioURL != "" {
+		keys = append(keys, "url:"+ep.AudioURL)
+	}
+	if seconds := DurationSeconds(ep.Duration); seconds > 0 {
+		title := strings.Join(strings.Fields(strings.ToLower(ep.Title)), " ")
+		keys = append(keys, "title:"+title+"|"+strconv.Itoa(seconds))
+	}
+	return keys
+}
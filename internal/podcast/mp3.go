@@ -0,0 +1,234 @@
+package podcast
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// mp3ScanLimit is how far past the ID3 tag MP3Duration looks for the first
+// audio frame
+const mp3ScanLimit = 64 * 1024
+
+// mp3Bitrates holds bitrates in kbps by [MPEG-1?][layer-1][index]
+var mp3Bitrates = [2][3][16]int{
+	{ // MPEG-2 and 2.5
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	},
+	{ // MPEG-1
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 0},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 0},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+	},
+}
+
+// mp3SampleRates holds sample rates in Hz by version bits and index
+var mp3SampleRates = map[byte][3]int{
+	3: {44100, 48000, 32000}, // MPEG-1
+	2: {22050, 24000, 16000}, // MPEG-2
+	0: {11025, 12000, 8000},  // MPEG-2.5
+}
+
+// mp3Frame is a decoded MPEG audio frame header
+type mp3Frame struct {
+	mpeg1      bool
+	layer      int // 1, 2 or 3
+	bitrate    int // bits per second
+	sampleRate int
+	mono       bool
+	length     int // bytes, header included
+}
+
+// samples is the number of audio samples in each frame
+func (f mp3Frame) samples() int {
+	switch {
+	case f.layer == 1:
+		return 384
+	case f.layer == 3 && !f.mpeg1:
+		return 576
+	}
+	return 1152
+}
+
+// parseMP3Frame decodes the four-byte frame header at the start of b
+func parseMP3Frame(b []byte) (mp3Frame, bool) {
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
+	}
+	version := (b[1] >> 3) & 3
+	layerBits := (b[1] >> 1) & 3
+	bitrateIndex := b[2] >> 4
+	rateIndex := (b[2] >> 2) & 3
+	rates, ok := mp3SampleRates[version]
+	if !ok || layerBits == 0 || rateIndex == 3 {
+		return mp3Frame{}, false
+	}
+
+	f := mp3Frame{
+		mpeg1:      version == 3,
+		layer:      4 - int(layerBits),
+		sampleRate: rates[rateIndex],
+		mono:       b[3]>>6 == 3,
+	}
+	v := 0
+	if f.mpeg1 {
+		v = 1
+	}
+	f.bitrate = mp3Bitrates[v][f.layer-1][bitrateIndex] * 1000
+	if f.bitrate == 0 {
+		return mp3Frame{}, false // free format or invalid
+	}
+
+	padding := int(b[2]>>1) & 1
+	switch {
+	case f.layer == 1:
+		f.length = (12*f.bitrate/f.sampleRate + padding) * 4
+	case f.layer == 3 && !f.mpeg1:
+		f.length = 72*f.bitrate/f.sampleRate + padding
+	default:
+		f.length = 144*f.bitrate/f.sampleRate + padding
+	}
+	return f, true
+}
+
+// vbrFrameCount reads the total frame count from a Xing/Info or VBRI header
+// in the first frame, which VBR encoders add since the bitrate varies
+func vbrFrameCount(frame []byte, f mp3Frame) (int, bool) {
+	// Xing sits after the side information, whose size depends on the
+	// version and channel count
+	side := 32
+	switch {
+	case f.mpeg1 && f.mono, !f.mpeg1 && !f.mono:
+		side = 17
+	case !f.mpeg1 && f.mono:
+		side = 9
+	}
+	if x := frame[min(4+side, len(frame)):]; len(x) >= 12 && (bytes.HasPrefix(x, []byte("Xing")) || bytes.HasPrefix(x, []byte("Info"))) {
+		if binary.BigEndian.Uint32(x[4:8])&1 != 0 {
+			return int(binary.BigEndian.Uint32(x[8:12])), true
+		}
+	}
+
+	// VBRI always sits 32 bytes after the header
+	if v := frame[min(36, len(frame)):]; len(v) >= 18 && bytes.HasPrefix(v, []byte("VBRI")) {
+		return int(binary.BigEndian.Uint32(v[14:18])), true
+	}
+	return 0, false
+}
+
+// id3v2Length is how many bytes the ID3v2 tag whose first 10 bytes are
+// header takes up: the header, its synchsafe size and an optional footer.
+// It is 0 when header doesn't start a tag.
+func id3v2Length(header []byte) int64 {
+	if len(header) < 10 || !bytes.HasPrefix(header, []byte("ID3")) {
+		return 0
+	}
+	size := int64(header[6]&0x7F)<<21 | int64(header[7]&0x7F)<<14 | int64(header[8]&0x7F)<<7 | int64(header[9]&0x7F)
+	length := 10 + size
+	if header[5]&0x10 != 0 {
+		length += 10
+	}
+	return length
+}
+
+// AudioSize is the size of the file at path less the ID3v2 tag at its
+// start, if any. Rewriting the tag doesn't change it.
+func AudioSize(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return fi.Size(), nil // too short to hold a tag
+	}
+	return max(fi.Size()-id3v2Length(header), 0), nil
+}
+
+// MP3Duration works out how long an MP3 file plays from its first audio
+// frame: exactly from a VBR header when the encoder wrote one, otherwise
+// from the constant bitrate and the file size
+func MP3Duration(path string) (time.Duration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	// Skip an ID3v2 tag
+	var start int64
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(f, header); err == nil {
+		start = id3v2Length(header)
+	}
+
+	buf := make([]byte, mp3ScanLimit)
+	n, err := f.ReadAt(buf, start)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	buf = buf[:n]
+
+	for i := 0; i+4 <= len(buf); i++ {
+		frame, ok := parseMP3Frame(buf[i:])
+		if !ok {
+			continue
+		}
+		// Trust a sync only when the next frame follows where it should
+		if next := i + frame.length; next+4 <= len(buf) {
+			if _, ok := parseMP3Frame(buf[next:]); !ok {
+				continue
+			}
+		}
+
+		if frames, ok := vbrFrameCount(buf[i:min(i+frame.length, len(buf))], frame); ok && frames > 0 {
+			seconds := float64(frames) * float64(frame.samples()) / float64(frame.sampleRate)
+			return time.Duration(seconds * float64(time.Second)), nil
+		}
+
+		audio := fi.Size() - start - int64(i)
+		if tail := make([]byte, 3); fi.Size() >= 128 {
+			if _, err := f.ReadAt(tail, fi.Size()-128); err == nil && string(tail) == "TAG" {
+				audio -= 128 // ID3v1 tag
+			}
+		}
+		seconds := float64(audio) * 8 / float64(frame.bitrate)
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	return 0, errors.New("no MP3 audio frames found")
+}
+
+// FormatDuration formats d as itunes:duration does, H:MM:SS or M:SS
+func FormatDuration(d time.Duration) string {
+	s := int(d.Round(time.Second) / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// FillDuration sets the duration of an episode whose feed gives none from
+// its downloaded MP3 file at path. Other formats are left as they are.
+func FillDuration(ep *Episode, path string) {
+	if ep.Duration != "" || !IsMP3(path) {
+		return
+	}
+	if d, err := MP3Duration(path); err == nil && d > 0 {
+		ep.Duration = FormatDuration(d)
+	}
+}
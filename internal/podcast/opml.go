@@ -0,0 +1,79 @@
+package podcast
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// opmlDocument is an OPML 2.0 subscription list
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Created string        `xml:"head>dateCreated"`
+	Outline []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Type     string        `xml:"type,attr"`
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	Outlines []opmlOutline `xml:"outline"` // apps group feeds in folders
+}
+
+// OPML renders the results that have a feed as an OPML subscription list,
+// the format podcast apps import
+func OPML(title string, results []SearchResult) ([]byte, error) {
+	doc := opmlDocument{Version: "2.0", Title: title, Created: time.Now().Format(time.RFC1123Z)}
+	for _, r := range results {
+		if r.FeedURL == "" {
+			continue
+		}
+		doc.Outline = append(doc.Outline, opmlOutline{Type: "rss", Text: r.Name, Title: r.Name, XMLURL: r.FeedURL})
+	}
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// IsOPMLFile reports whether s names an OPML file on disk
+func IsOPMLFile(s string) bool {
+	return strings.EqualFold(filepath.Ext(s), ".opml") && IsFeedFile(s)
+}
+
+// ReadOPML reads the feeds of an OPML subscription list, folders included,
+// as search results to load with LoadFeed
+func ReadOPML(path string) ([]SearchResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc opmlDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OPML: %w", err)
+	}
+
+	var results []SearchResult
+	var walk func([]opmlOutline)
+	walk = func(outlines []opmlOutline) {
+		for _, o := range outlines {
+			if o.XMLURL != "" {
+				name := o.Title
+				if name == "" {
+					name = o.Text
+				}
+				results = append(results, SearchResult{Name: name, FeedURL: o.XMLURL})
+			}
+			walk(o.Outlines)
+		}
+	}
+	walk(doc.Outline)
+	return results, nil
+}
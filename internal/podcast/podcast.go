@@ -0,0 +1,176 @@
+// Package podcast is the core of podcastdownload: searching the Apple and
+// Podcast Index directories, loading feeds, and downloading and tagging
+// episodes. It has no knowledge of the terminal UI.
+package podcast
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// PodcastInfo holds metadata from Apple's API
+type PodcastInfo struct {
+	Name       string
+	Artist     string
+	FeedURL    string
+	ArtworkURL string
+	ID         string
+	MovedFrom  string     // original feed URL when the feed has permanently moved
+	NewFeedURL string     // where the feed says it moved, with itunes:new-feed-url
+	Blocked    bool       // the publisher set itunes:block on the feed
+	MergedFrom []string   // other listings' feeds whose episodes were merged in
+	Validators Validators // what the feed was served with, to ask later whether it changed
+	Feed       []byte     // the feed document as served, for --save-feed
+
+	// Show-level metadata from the feed
+	Description string      // plain text
+	Language    string      // as the feed gives it, e.g. en-us
+	Categories  []string    // iTunes categories, "Category > Subcategory"
+	LastBuild   time.Time   // the feed's lastBuildDate; zero when it gives none
+	Funding     []Funding   // podcast:funding links to support the show
+	Value       *ValueBlock // podcast:value payment details; nil when absent
+	Trailers    []Episode   // podcast:trailer clips, as episodes to download
+}
+
+// FeedMoved records that the feed permanently moved to feedURL
+func (p *PodcastInfo) FeedMoved(feedURL string) {
+	if feedURL == "" || feedURL == p.FeedURL {
+		return
+	}
+	p.MovedFrom, p.FeedURL = p.FeedURL, feedURL
+}
+
+// SearchResult holds a podcast from search results
+type SearchResult struct {
+	ID         string
+	Name       string
+	Artist     string
+	FeedURL    string
+	ArtworkURL string
+	Source     SearchProvider // which index this result came from
+}
+
+// Episode holds episode data from RSS feed
+type Episode struct {
+	Index        int
+	GUID         string
+	Title        string
+	Description  string // show notes as the feed gives them, HTML included
+	Author       string // itunes:author of the episode, if it differs per episode
+	AudioURL     string
+	AudioType    string // enclosure MIME type, e.g. audio/mpeg
+	ExpectedSize int64  // enclosure length in bytes; 0 when the feed doesn't say
+	PubDate      time.Time
+	Duration     string
+	Blocked      bool     // the publisher set itunes:block on the episode
+	Explicit     bool     // itunes:explicit, from the episode or else the feed
+	People       []Person // podcast:person entries: hosts, guests and crew
+	Selected     bool
+}
+
+// PlainDescription returns the show notes as plain text, keeping paragraph
+// and line breaks. Cleaning is left until the notes are shown since large
+// feeds carry long HTML notes for thousands of episodes.
+func (e Episode) PlainDescription() string {
+	return cleanHTML(e.Description)
+}
+
+// SizeMismatch reports whether a download of size bytes is more than 10%
+// off the size the feed announced
+func (e Episode) SizeMismatch(size int64) bool {
+	if e.ExpectedSize <= 0 {
+		return false
+	}
+	return size < e.ExpectedSize*9/10 || size > e.ExpectedSize*11/10
+}
+
+// Funding is a link to support a show, from podcast:funding
+type Funding struct {
+	URL  string
+	Text string // the link's label, e.g. "Support us on Patreon"; may be empty
+}
+
+// ValueBlock describes the podcast:value ("value for value") payments a
+// show accepts, e.g. lightning keysend split between its recipients
+type ValueBlock struct {
+	Type       string   // e.g. lightning
+	Method     string   // e.g. keysend
+	Recipients []string // names of those receiving a split
+}
+
+// Describe summarizes a value block, e.g. "lightning (keysend) to Alice, Bob"
+func (v ValueBlock) Describe() string {
+	s := v.Type
+	if s == "" {
+		s = "payments"
+	}
+	if v.Method != "" {
+		s += " (" + v.Method + ")"
+	}
+	if len(v.Recipients) > 0 {
+		s += " to " + strings.Join(v.Recipients, ", ")
+	}
+	return s
+}
+
+// Person is someone credited on an episode with podcast:person
+type Person struct {
+	Name string
+	Role string // lowercased, "host" when the feed doesn't say
+}
+
+// PeopleWithRole returns the names of the people credited with role
+func (e Episode) PeopleWithRole(role string) []string {
+	var names []string
+	for _, p := range e.People {
+		if p.Role == role {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// SearchProvider indicates which podcast index to use
+type SearchProvider string
+
+const (
+	ProviderApple        SearchProvider = "apple"
+	ProviderPodcastIndex SearchProvider = "podcastindex"
+)
+
+// BlockedEpisodes counts the episodes the publisher marked itunes:block
+func BlockedEpisodes(episodes []Episode) int {
+	n := 0
+	for _, ep := range episodes {
+		if ep.Blocked {
+			n++
+		}
+	}
+	return n
+}
+
+// IsID checks if a string is all digits (podcast ID)
+func IsID(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+// IsFeedURL reports whether the input is a direct RSS feed URL
+func IsFeedURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// IsFeedFile reports whether s names a feed saved on disk, such as one
+// written by --save-feed
+func IsFeedFile(s string) bool {
+	if IsFeedURL(s) {
+		return false
+	}
+	fi, err := os.Stat(s)
+	return err == nil && fi.Mode().IsRegular()
+}
@@ -0,0 +1,112 @@
+package podcast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecentEpisode is an episode from Podcast Index's recent episodes, with
+// the podcast it belongs to
+type RecentEpisode struct {
+	Episode
+	Podcast PodcastInfo
+}
+
+// recentEpisodesResponse is the reply of /recent/episodes
+type recentEpisodesResponse struct {
+	Items []struct {
+		ID              int64  `json:"id"`
+		Title           string `json:"title"`
+		Description     string `json:"description"`
+		GUID            string `json:"guid"`
+		DatePublished   int64  `json:"datePublished"`
+		EnclosureURL    string `json:"enclosureUrl"`
+		EnclosureType   string `json:"enclosureType"`
+		EnclosureLength int64  `json:"enclosureLength"`
+		Duration        int    `json:"duration"`
+		Image           string `json:"image"`
+		FeedID          int64  `json:"feedId"`
+		FeedTitle       string `json:"feedTitle"`
+		FeedImage       string `json:"feedImage"`
+		FeedLanguage    string `json:"feedLanguage"`
+	} `json:"items"`
+}
+
+// RecentEpisodes fetches up to max of the episodes most recently published
+// across Podcast Index, newest first. before pages back through them: 0
+// starts at the newest, and the returned cursor continues after the last
+// episode returned, or is 0 when there are no more.
+func RecentEpisodes(max int, before int64) ([]RecentEpisode, int64, error) {
+	if !HasPodcastIndexCredentials() {
+		return nil, 0, fmt.Errorf("Podcast Index API credentials not set (PODCASTINDEX_API_KEY, PODCASTINDEX_API_SECRET)")
+	}
+	params := url.Values{"max": {strconv.Itoa(max)}, "fulltext": {""}}
+	if before > 0 {
+		params.Set("before", strconv.FormatInt(before, 10))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	req, err := newPodcastIndexRequest(ctx, "/recent/episodes", params)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := doWith(httpClient, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, 0, fmt.Errorf("Podcast Index API error (%d): %s", resp.StatusCode, string(body))
+	}
+
+	var result recentEpisodesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse recent episodes: %w", err)
+	}
+
+	var episodes []RecentEpisode
+	var next int64
+	for _, item := range result.Items {
+		next = item.ID
+		if item.EnclosureURL == "" {
+			continue
+		}
+		ep := RecentEpisode{
+			Episode: Episode{
+				GUID:         strings.TrimSpace(item.GUID),
+				Title:        item.Title,
+				Description:  item.Description,
+				AudioURL:     item.EnclosureURL,
+				AudioType:    item.EnclosureType,
+				ExpectedSize: item.EnclosureLength,
+			},
+			Podcast: PodcastInfo{
+				ID:         strconv.FormatInt(item.FeedID, 10),
+				Name:       item.FeedTitle,
+				ArtworkURL: item.FeedImage,
+				Language:   item.FeedLanguage,
+			},
+		}
+		if item.DatePublished > 0 {
+			ep.PubDate = time.Unix(item.DatePublished, 0)
+		}
+		if item.Duration > 0 {
+			ep.Duration = FormatDuration(time.Duration(item.Duration) * time.Second)
+		}
+		episodes = append(episodes, ep)
+	}
+	if len(result.Items) < max {
+		next = 0 // the index has nothing older to give
+	}
+	return episodes, next, nil
+}
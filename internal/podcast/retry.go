@@ -0,0 +1,77 @@
+package podcast
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxRetries is how many times a rate-limited request is retried
+	maxRetries = 3
+
+	// retryBackoff is the first wait when the server gives no Retry-After;
+	// it doubles on each attempt
+	retryBackoff = 2 * time.Second
+
+	// maxRetryWait caps how long a single Retry-After may make us wait
+	maxRetryWait = 5 * time.Minute
+)
+
+// get fetches url, retrying when the server answers 429 Too Many Requests.
+// The wait honors the Retry-After header when present and falls back to
+// exponential backoff otherwise. The last response is returned as is.
+func get(url string) (*http.Response, error) {
+	return getWith(httpClient, url)
+}
+
+// getWith is get using the given client
+func getWith(client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return doWith(client, req)
+}
+
+// doWith sends req with client, retrying like get. req must have no body.
+// Cancelling req's context also ends a wait between attempts.
+func doWith(client *http.Client, req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, asNetworkError(err)
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt == maxRetries {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok {
+			wait = retryBackoff << attempt
+		}
+		timer := time.NewTimer(min(wait, maxRetryWait))
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// retryAfter parses a Retry-After header, given either as a number of
+// seconds or as an HTTP date
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
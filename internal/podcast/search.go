@@ -0,0 +1,335 @@
+package podcast
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPodcastIndexBaseURL is the public Podcast Index API endpoint
+const DefaultPodcastIndexBaseURL = "https://api.podcastindex.org/api/1.0"
+
+// PodcastIndexBaseURL is the Podcast Index API base, overridable for self-hosted or mirror instances
+var PodcastIndexBaseURL = DefaultPodcastIndexBaseURL
+
+// AppleCountry is the two-letter code of the Apple store searched and
+// looked up; empty leaves the choice to Apple (the US store)
+var AppleCountry string
+
+// appleCountryParam is the query parameter selecting AppleCountry
+func appleCountryParam() string {
+	if AppleCountry == "" {
+		return ""
+	}
+	return "&country=" + strings.ToLower(AppleCountry)
+}
+
+// iTunesResponse represents Apple's lookup API response
+type iTunesResponse struct {
+	ResultCount int `json:"resultCount"`
+	Results     []struct {
+		WrapperType    string `json:"wrapperType"` // "track" for shows, "podcastEpisode" for episodes
+		Kind           string `json:"kind"`        // "podcast" or "podcast-episode"
+		CollectionID   int    `json:"collectionId"`
+		CollectionName string `json:"collectionName"`
+		ArtistName     string `json:"artistName"`
+		FeedURL        string `json:"feedUrl"`
+		ArtworkURL600  string `json:"artworkUrl600"`
+		ArtworkURL100  string `json:"artworkUrl100"`
+	} `json:"results"`
+}
+
+// podcastIndexResponse represents Podcast Index API search response
+type podcastIndexResponse struct {
+	Status string `json:"status"`
+	Feeds  []struct {
+		ID          int    `json:"id"`
+		Title       string `json:"title"`
+		Author      string `json:"author"`
+		URL         string `json:"url"`
+		Image       string `json:"image"`
+		Description string `json:"description"`
+	} `json:"feeds"`
+	Count int `json:"count"`
+}
+
+// HasPodcastIndexCredentials checks if Podcast Index API credentials are set
+func HasPodcastIndexCredentials() bool {
+	apiKey := strings.TrimSpace(os.Getenv("PODCASTINDEX_API_KEY"))
+	apiSecret := strings.TrimSpace(os.Getenv("PODCASTINDEX_API_SECRET"))
+	return apiKey != "" && apiSecret != ""
+}
+
+// PodcastIndexKey returns the Podcast Index API key in use, or ""
+func PodcastIndexKey() string {
+	return strings.TrimSpace(os.Getenv("PODCASTINDEX_API_KEY"))
+}
+
+// CheckPodcastIndex makes a minimal authenticated request to verify that
+// the Podcast Index credentials are accepted
+func CheckPodcastIndex() error {
+	if !HasPodcastIndexCredentials() {
+		return fmt.Errorf("Podcast Index API credentials not set (PODCASTINDEX_API_KEY, PODCASTINDEX_API_SECRET)")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	req, err := newPodcastIndexRequest(ctx, "/search/byterm", url.Values{"q": {"podcast"}, "max": {"1"}})
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := doWith(httpClient, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// The API explains rejections in a JSON description
+		var reply struct {
+			Description string `json:"description"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&reply) != nil || reply.Description == "" {
+			reply.Description = resp.Status
+		}
+		return fmt.Errorf("Podcast Index API error (%d): %s", resp.StatusCode, reply.Description)
+	}
+	return nil
+}
+
+// newPodcastIndexRequest builds an authenticated GET request for a Podcast Index
+// API endpoint, relative to PodcastIndexBaseURL
+func newPodcastIndexRequest(ctx context.Context, endpoint string, params url.Values) (*http.Request, error) {
+	apiKey := strings.TrimSpace(os.Getenv("PODCASTINDEX_API_KEY"))
+	apiSecret := strings.TrimSpace(os.Getenv("PODCASTINDEX_API_SECRET"))
+
+	// Build authentication headers (hash = sha1(apiKey + apiSecret + unixTime))
+	apiHeaderTime := strconv.FormatInt(time.Now().Unix(), 10)
+	hashInput := apiKey + apiSecret + apiHeaderTime
+	h := sha1.New()
+	h.Write([]byte(hashInput))
+	authHash := hex.EncodeToString(h.Sum(nil))
+
+	apiURL := PodcastIndexBaseURL + endpoint
+	if len(params) > 0 {
+		apiURL += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set required headers
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Auth-Key", apiKey)
+	req.Header.Set("X-Auth-Date", apiHeaderTime)
+	req.Header.Set("Authorization", authHash)
+	return req, nil
+}
+
+// Search searches the directory selected by provider. Apple searches also
+// include Podcast Index when credentials are available.
+func Search(query string, provider SearchProvider) ([]SearchResult, error) {
+	if HasPodcastIndexCredentials() && provider == ProviderApple {
+		return SearchBoth(query)
+	} else if provider == ProviderPodcastIndex {
+		if !HasPodcastIndexCredentials() {
+			return nil, fmt.Errorf("Podcast Index API credentials not set (PODCASTINDEX_API_KEY, PODCASTINDEX_API_SECRET)")
+		}
+		return SearchPodcastIndex(query)
+	}
+	return SearchApple(query)
+}
+
+// SearchApple searches for podcasts using Apple's Search API
+func SearchApple(query string) ([]SearchResult, error) {
+	// URL encode the query
+	encodedQuery := strings.ReplaceAll(query, " ", "+")
+	url := fmt.Sprintf("https://itunes.apple.com/search?term=%s&media=podcast&limit=25", encodedQuery) + appleCountryParam()
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := doWith(httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result iTunesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse search results: %w", err)
+	}
+
+	var results []SearchResult
+	for _, r := range result.Results {
+		if r.FeedURL == "" {
+			continue // Skip podcasts without RSS feed
+		}
+		results = append(results, SearchResult{
+			ID:         strconv.Itoa(r.CollectionID),
+			Name:       r.CollectionName,
+			Artist:     r.ArtistName,
+			FeedURL:    r.FeedURL,
+			ArtworkURL: appleArtworkURL(r.ArtworkURL100, r.ArtworkURL600),
+			Source:     ProviderApple,
+		})
+	}
+	return results, nil
+}
+
+// SearchPodcastIndex searches using Podcast Index API
+func SearchPodcastIndex(query string) ([]SearchResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	req, err := newPodcastIndexRequest(ctx, "/search/byterm", url.Values{"q": {query}, "max": {"25"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := doWith(httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Podcast Index API error (%d): %s", resp.StatusCode, string(body))
+	}
+
+	var result podcastIndexResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse search results: %w", err)
+	}
+
+	var results []SearchResult
+	for _, feed := range result.Feeds {
+		if feed.URL == "" {
+			continue
+		}
+		results = append(results, SearchResult{
+			ID:         strconv.Itoa(feed.ID),
+			Name:       feed.Title,
+			Artist:     feed.Author,
+			FeedURL:    feed.URL,
+			ArtworkURL: feed.Image,
+			Source:     ProviderPodcastIndex,
+		})
+	}
+	return results, nil
+}
+
+// SearchBoth searches both Apple and Podcast Index APIs concurrently and combines results
+func SearchBoth(query string) ([]SearchResult, error) {
+	var wg sync.WaitGroup
+	var appleResults, piResults []SearchResult
+	var appleErr, piErr error
+
+	wg.Add(2)
+
+	// Search Apple
+	go func() {
+		defer wg.Done()
+		appleResults, appleErr = SearchApple(query)
+	}()
+
+	// Search Podcast Index
+	go func() {
+		defer wg.Done()
+		piResults, piErr = SearchPodcastIndex(query)
+	}()
+
+	wg.Wait()
+
+	// If both failed, return error
+	if appleErr != nil && piErr != nil {
+		return nil, fmt.Errorf("search failed: Apple: %w, Podcast Index: %w", appleErr, piErr)
+	}
+
+	// Combine results - Apple first, then Podcast Index (deduplicated by feed URL)
+	var combined []SearchResult
+	seenFeedURLs := make(map[string]bool)
+
+	if appleErr == nil {
+		for _, r := range appleResults {
+			normalizedURL := normalizeFeedURL(r.FeedURL)
+			if !seenFeedURLs[normalizedURL] {
+				seenFeedURLs[normalizedURL] = true
+				combined = append(combined, r)
+			}
+		}
+	}
+	if piErr == nil {
+		for _, r := range piResults {
+			normalizedURL := normalizeFeedURL(r.FeedURL)
+			if !seenFeedURLs[normalizedURL] {
+				seenFeedURLs[normalizedURL] = true
+				combined = append(combined, r)
+			}
+		}
+	}
+
+	return combined, nil
+}
+
+// FilterByAuthor keeps the results whose artist contains author, ignoring case
+func FilterByAuthor(results []SearchResult, author string) []SearchResult {
+	author = strings.ToLower(strings.TrimSpace(author))
+	var filtered []SearchResult
+	for _, r := range results {
+		if strings.Contains(strings.ToLower(r.Artist), author) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// normalizeFeedURL reduces a feed URL to a form for comparing feeds listed
+// by different directories
+func normalizeFeedURL(feedURL string) string {
+	return strings.ToLower(strings.TrimSuffix(feedURL, "/"))
+}
+
+// ResolveAppleID finds the Apple Podcasts ID of a feed. Apple can't be
+// searched by feed URL, so the feed's title is searched and the result
+// listing the same feed is picked.
+func ResolveAppleID(feedURL string) (string, error) {
+	feed, movedTo, err := ParseFeed(feedURL)
+	if err != nil {
+		return "", err
+	}
+	if feed.Title == "" {
+		return "", fmt.Errorf("feed has no title to search Apple Podcasts for")
+	}
+
+	results, err := SearchApple(feed.Title)
+	if err != nil {
+		return "", fmt.Errorf("failed to search Apple Podcasts: %w", err)
+	}
+
+	wanted := map[string]bool{normalizeFeedURL(feedURL): true}
+	if movedTo != "" {
+		wanted[normalizeFeedURL(movedTo)] = true
+	}
+	for _, r := range results {
+		if wanted[normalizeFeedURL(r.FeedURL)] {
+			return r.ID, nil
+		}
+	}
+	return "", fmt.Errorf("%w on Apple Podcasts for %s (searched for %q)", ErrNotFound, feedURL, feed.Title)
+}
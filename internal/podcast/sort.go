@@ -0,0 +1,264 @@
+package podcast
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SortKey is an episode ordering accepted by --sort-by
+type SortKey string
+
+const (
+	SortDate     SortKey = "date"     // newest first
+	SortTitle    SortKey = "title"    // A to Z
+	SortDuration SortKey = "duration" // shortest first
+)
+
+// SortKeys lists the available orderings
+var SortKeys = []SortKey{SortDate, SortTitle, SortDuration}
+
+// ParseSortKey validates a --sort-by value
+func ParseSortKey(s string) (SortKey, error) {
+	for _, key := range SortKeys {
+		if strings.EqualFold(s, string(key)) {
+			return key, nil
+		}
+	}
+	return "", fmt.Errorf("unknown sort order %q (available: date, title, duration)", s)
+}
+
+// SortEpisodes orders episodes in place, reversed if asked, and renumbers
+// their Index to match so file names and track tags follow the new order
+func SortEpisodes(episodes []Episode, key SortKey, reverse bool) {
+	less := func(a, b Episode) bool {
+		switch key {
+		case SortTitle:
+			return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+		case SortDuration:
+			return DurationSeconds(a.Duration) < DurationSeconds(b.Duration)
+		default:
+			return a.PubDate.After(b.PubDate)
+		}
+	}
+
+	sort.SliceStable(episodes, func(i, j int) bool {
+		if reverse {
+			return less(episodes[j], episodes[i])
+		}
+		return less(episodes[i], episodes[j])
+	})
+
+	Renumber(episodes)
+}
+
+// Renumber sets the episodes' Index to 1..N in their current order
+func Renumber(episodes []Episode) {
+	for i := range episodes {
+		episodes[i].Index = i + 1
+	}
+}
+
+// DownloadOrder is the order selected episodes are fetched in, which is
+// independent of their numbering
+type DownloadOrder string
+
+const (
+	OrderSelection DownloadOrder = "selection" // as listed
+	OrderNewest    DownloadOrder = "newest"    // most recent first
+	OrderOldest    DownloadOrder = "oldest"    // oldest first
+)
+
+// ParseDownloadOrder validates a --download-order value
+func ParseDownloadOrder(s string) (DownloadOrder, error) {
+	for _, order := range []DownloadOrder{OrderSelection, OrderNewest, OrderOldest} {
+		if strings.EqualFold(s, string(order)) {
+			return order, nil
+		}
+	}
+	return "", fmt.Errorf("unknown download order %q (available: newest, oldest, selection)", s)
+}
+
+// OrderForDownload puts episodes in download order in place, keeping their
+// Index; undated episodes go last
+func OrderForDownload(episodes []Episode, order DownloadOrder) {
+	if order != OrderNewest && order != OrderOldest {
+		return
+	}
+	sort.SliceStable(episodes, func(i, j int) bool {
+		a, b := episodes[i].PubDate, episodes[j].PubDate
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+		if order == OrderNewest {
+			return a.After(b)
+		}
+		return a.Before(b)
+	})
+}
+
+// EpisodeRange is an inclusive range of episode numbers
+type EpisodeRange struct {
+	First, Last int
+}
+
+// ParseEpisodeRanges parses a comma-separated list of episode numbers and
+// ranges, such as "1-5,12,20"
+func ParseEpisodeRanges(expr string) ([]EpisodeRange, error) {
+	var ranges []EpisodeRange
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		if !isRange {
+			last = first
+		}
+		lo, err1 := strconv.Atoi(strings.TrimSpace(first))
+		hi, err2 := strconv.Atoi(strings.TrimSpace(last))
+		if err1 != nil || err2 != nil || lo < 1 || hi < lo {
+			return nil, fmt.Errorf("invalid episode number or range %q (expected e.g. 1-5,12,20)", part)
+		}
+		ranges = append(ranges, EpisodeRange{First: lo, Last: hi})
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no episode numbers given")
+	}
+	return ranges, nil
+}
+
+// InRanges reports whether episode number n falls in any of ranges
+func InRanges(ranges []EpisodeRange, n int) bool {
+	for _, r := range ranges {
+		if n >= r.First && n <= r.Last {
+			return true
+		}
+	}
+	return false
+}
+
+// DropFuture removes the episodes dated after now, which feeds use for
+// scheduled or placeholder entries, and returns how many it removed.
+// Undated episodes are kept, and the rest keep their numbers.
+func DropFuture(episodes []Episode, now time.Time) ([]Episode, int) {
+	kept := episodes[:0:0]
+	for _, ep := range episodes {
+		if ep.PubDate.After(now) {
+			continue
+		}
+		kept = append(kept, ep)
+	}
+	return kept, len(episodes) - len(kept)
+}
+
+// Window is a span of time back from now, as given to --within, e.g. 30d
+type Window struct {
+	n    int
+	unit string // h, d, w, mo or y
+}
+
+// windowPattern matches a --within value such as 30d or 6mo
+var windowPattern = regexp.MustCompile(`^(\d+)\s*(h|d|w|mo|y)$`)
+
+// ParseWindow validates a --within value: a count followed by h (hours),
+// d (days), w (weeks), mo (months) or y (years)
+func ParseWindow(s string) (*Window, error) {
+	m := windowPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return nil, fmt.Errorf("invalid window %q (expected e.g. 48h, 30d, 2w, 6mo or 1y)", s)
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n == 0 {
+		return nil, fmt.Errorf("invalid window %q (expected a count of at least 1)", s)
+	}
+	return &Window{n: n, unit: m[2]}, nil
+}
+
+// Cutoff is the start of the window ending at now. Months and years are
+// calendar months and years.
+func (w Window) Cutoff(now time.Time) time.Time {
+	switch w.unit {
+	case "h":
+		return now.Add(-time.Duration(w.n) * time.Hour)
+	case "w":
+		return now.AddDate(0, 0, -7*w.n)
+	case "mo":
+		return now.AddDate(0, -w.n, 0)
+	case "y":
+		return now.AddDate(-w.n, 0, 0)
+	}
+	return now.AddDate(0, 0, -w.n)
+}
+
+func (w Window) String() string {
+	return strconv.Itoa(w.n) + w.unit
+}
+
+// DropBefore removes the episodes published before cutoff and returns how
+// many it removed. Undated episodes can't be shown to be recent, so they
+// are removed too; the rest keep their numbers.
+func DropBefore(episodes []Episode, cutoff time.Time) ([]Episode, int) {
+	kept := episodes[:0:0]
+	for _, ep := range episodes {
+		if ep.PubDate.IsZero() || ep.PubDate.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, ep)
+	}
+	return kept, len(episodes) - len(kept)
+}
+
+// Newest returns the most recently published episode
+func Newest(episodes []Episode) (Episode, bool) {
+	if len(episodes) == 0 {
+		return Episode{}, false
+	}
+	newest := episodes[0]
+	for _, ep := range episodes[1:] {
+		if ep.PubDate.After(newest.PubDate) {
+			newest = ep
+		}
+	}
+	return newest, true
+}
+
+// UpdateInterval estimates how often a show publishes: the median gap
+// between consecutive dated episodes. ok is false with fewer than two
+// dated episodes.
+func UpdateInterval(episodes []Episode) (interval time.Duration, ok bool) {
+	var dates []time.Time
+	for _, ep := range episodes {
+		if !ep.PubDate.IsZero() {
+			dates = append(dates, ep.PubDate)
+		}
+	}
+	if len(dates) < 2 {
+		return 0, false
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	gaps := make([]time.Duration, 0, len(dates)-1)
+	for i := 1; i < len(dates); i++ {
+		gaps = append(gaps, dates[i].Sub(dates[i-1]))
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	return gaps[len(gaps)/2], true
+}
+
+// DurationSeconds parses an itunes:duration value, given either as
+// seconds or as [HH:]MM:SS. Unparseable durations count as zero.
+func DurationSeconds(d string) int {
+	total := 0
+	for _, part := range strings.Split(strings.TrimSpace(d), ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0
+		}
+		total = total*60 + n
+	}
+	return total
+}
@@ -0,0 +1,48 @@
+package podcast
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TrackingPrefixes match the analytics redirects that publishers put in
+// front of enclosure URLs, written without the scheme, such as
+// dts.podtrac.com/redirect.mp3/. Each is followed by the URL it redirects
+// to, and several are often chained. Add to it for prefixes not listed.
+var TrackingPrefixes = []*regexp.Regexp{
+	regexp.MustCompile(`^(dts\.|www\.)?podtrac\.com/(pts/)?redirect\.[a-z0-9]+/`),
+	regexp.MustCompile(`^(chrt\.fm|chtbl\.com)/track/[^/]+/`),
+	regexp.MustCompile(`^pdst\.fm/e/`),
+	regexp.MustCompile(`^op3\.dev/e(,[^/]*)?/`),
+	regexp.MustCompile(`^(pfx\.vpixl\.com|mgln\.ai/e|arttrk\.com/p)/[^/]+/`),
+	regexp.MustCompile(`^prfx\.byspotify\.com/e/`),
+	regexp.MustCompile(`^(verifi\.podscribe\.com|pscrb\.fm)/rss/p/`),
+	regexp.MustCompile(`^(www\.)?clrtpod\.com/m/[^/]+/`),
+	regexp.MustCompile(`^growx\.podkite\.com/[^/]+/`),
+}
+
+// MediaURL unwraps an enclosure URL from the tracking redirects in
+// TrackingPrefixes, giving the URL of the media itself. Downloads still use
+// the original URL, so the publisher's statistics are kept; this one is for
+// telling the file's type and showing where it is hosted.
+func MediaURL(audioURL string) string {
+	scheme, rest, ok := strings.Cut(audioURL, "://")
+	if !ok {
+		return audioURL
+	}
+	for unwrapped := true; unwrapped; {
+		unwrapped = false
+		for _, prefix := range TrackingPrefixes {
+			if loc := prefix.FindStringIndex(rest); loc != nil && loc[1] < len(rest) {
+				rest = rest[loc[1]:]
+				// Some prefixes keep the scheme of the URL they wrap
+				if s, r, ok := strings.Cut(rest, "://"); ok && !strings.Contains(s, "/") {
+					scheme, rest = s, r
+				}
+				unwrapped = true
+				break
+			}
+		}
+	}
+	return scheme + "://" + rest
+}
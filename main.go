@@ -1,259 +1,491 @@
 package main
 
 import (
-	"crypto/sha1"
-	"encoding/hex"
-	"encoding/json"
+	"bufio"
+	"crypto/sha256"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"net/http"
+	"math/rand/v2"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/bogem/id3v2"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mmcdole/gofeed"
-)
-
-// Global program reference for sending messages from goroutines
-var program *tea.Program
-
-// Styles
-var (
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("205")).
-			MarginBottom(1)
-
-	subtitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240"))
-
-	selectedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205")).
-			Bold(true)
-
-	normalStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252"))
+	"github.com/muesli/termenv"
 
-	dimStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240"))
+	"podcastdownload/internal/podcast"
+)
 
-	checkboxStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205"))
+// theme holds the TUI styles, selected at startup with --theme
+type theme struct {
+	title    lipgloss.Style
+	subtitle lipgloss.Style
+	selected lipgloss.Style
+	normal   lipgloss.Style
+	dim      lipgloss.Style
+	checkbox lipgloss.Style
+	help     lipgloss.Style
+	error    lipgloss.Style
+	success  lipgloss.Style
+	spinner  lipgloss.Style
+	progress []progress.Option
+}
 
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			MarginTop(1)
+// palette is the set of colors a color theme is built from
+type palette struct {
+	accent, subtle, text, dim, help, error, success string
+}
 
-	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
-			Bold(true)
+var (
+	// darkPalette is the original palette, suited to dark terminals
+	darkPalette = palette{accent: "205", subtle: "240", text: "252", dim: "240", help: "241", error: "196", success: "82"}
 
-	successStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("82")).
-			Bold(true)
+	// lightPalette keeps text readable on light terminal backgrounds
+	lightPalette = palette{accent: "125", subtle: "242", text: "235", dim: "245", help: "244", error: "160", success: "28"}
 )
 
-// PodcastInfo holds metadata from Apple's API
-type PodcastInfo struct {
-	Name       string
-	Artist     string
-	FeedURL    string
-	ArtworkURL string
-	ID         string
-}
-
-// SearchResult holds a podcast from search results
-type SearchResult struct {
-	ID         string
-	Name       string
-	Artist     string
-	FeedURL    string
-	ArtworkURL string
-	Source     SearchProvider // which index this result came from
-}
-
-// Episode holds episode data from RSS feed
-type Episode struct {
-	Index       int
-	Title       string
-	Description string
-	AudioURL    string
-	PubDate     time.Time
-	Duration    string
-	Selected    bool
-}
-
-// iTunesResponse represents Apple's lookup API response
-type iTunesResponse struct {
-	ResultCount int `json:"resultCount"`
-	Results     []struct {
-		CollectionID   int    `json:"collectionId"`
-		CollectionName string `json:"collectionName"`
-		ArtistName     string `json:"artistName"`
-		FeedURL        string `json:"feedUrl"`
-		ArtworkURL600  string `json:"artworkUrl600"`
-		ArtworkURL100  string `json:"artworkUrl100"`
-	} `json:"results"`
-}
-
-// podcastIndexResponse represents Podcast Index API search response
-type podcastIndexResponse struct {
-	Status string `json:"status"`
-	Feeds  []struct {
-		ID          int    `json:"id"`
-		Title       string `json:"title"`
-		Author      string `json:"author"`
-		URL         string `json:"url"`
-		Image       string `json:"image"`
-		Description string `json:"description"`
-	} `json:"feeds"`
-	Count int `json:"count"`
-}
-
-// SearchProvider indicates which podcast index to use
-type SearchProvider string
+// themeNames lists the values accepted by --theme
+var themeNames = []string{"default", "light", "mono"}
+
+// themeByName returns the named theme
+func themeByName(name string) (theme, error) {
+	switch strings.ToLower(name) {
+	case "", "default", "dark":
+		return colorTheme(darkPalette), nil
+	case "light":
+		return colorTheme(lightPalette), nil
+	case "mono", "none":
+		return monoTheme(), nil
+	}
+	return theme{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(themeNames, ", "))
+}
 
-const (
-	ProviderApple        SearchProvider = "apple"
-	ProviderPodcastIndex SearchProvider = "podcastindex"
-)
+// colorTheme builds the styles for a color palette
+func colorTheme(p palette) theme {
+	return theme{
+		title: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(p.accent)).
+			MarginBottom(1),
+		subtitle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.subtle)),
+		selected: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.accent)).
+			Bold(true),
+		normal: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.text)),
+		dim: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.dim)),
+		checkbox: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.accent)),
+		help: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.help)).
+			MarginTop(1),
+		error: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.error)).
+			Bold(true),
+		success: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.success)).
+			Bold(true),
+		spinner:  lipgloss.NewStyle().Foreground(lipgloss.Color(p.accent)),
+		progress: []progress.Option{progress.WithDefaultGradient()},
+	}
+}
+
+// monoTheme uses only text attributes, for NO_COLOR and --no-color
+func monoTheme() theme {
+	return theme{
+		title:    lipgloss.NewStyle().Bold(true).Underline(true).MarginBottom(1),
+		subtitle: lipgloss.NewStyle().Faint(true),
+		selected: lipgloss.NewStyle().Bold(true).Reverse(true),
+		normal:   lipgloss.NewStyle(),
+		dim:      lipgloss.NewStyle().Faint(true),
+		checkbox: lipgloss.NewStyle(),
+		help:     lipgloss.NewStyle().Faint(true).MarginTop(1),
+		error:    lipgloss.NewStyle().Bold(true),
+		success:  lipgloss.NewStyle().Bold(true),
+		spinner:  lipgloss.NewStyle(),
+		progress: []progress.Option{progress.WithColorProfile(termenv.Ascii)},
+	}
+}
 
 // App states
 type state int
 
 const (
 	stateLoading state = iota
+	stateSearchInput
 	stateSearchResults
 	statePreviewPodcast
 	stateSelecting
 	statePreviewEpisode
+	stateEditDir
+	stateConfirm
 	stateDownloading
 	stateDone
 	stateError
+	stateHelp          // keyboard help overlay over helpReturn
+	stateSelectNumbers // typing episode numbers to toggle
 )
 
 // Model is our Bubble Tea model
 type model struct {
 	state          state
+	helpReturn     state // the screen under the help overlay
+	artworkErr     error // why the cover couldn't be embedded or saved, with --embed-artwork or --cover
+	feedErr        error // why the feed couldn't be saved, with --save-feed
+	stopErr        error // the error that stopped the downloads before the end
+	futureHidden   int   // episodes dated in the future, left out of the list
+	olderHidden    int   // episodes published before the --within window
+	duplicates     int   // repeated episodes collapsed by --dedup-episodes
 	podcastID      string
 	searchQuery    string
-	searchResults  []SearchResult
-	podcastInfo    PodcastInfo
-	episodes       []Episode
+	searchResults  []podcast.SearchResult
+	hiddenResults  []hiddenResult // results hidden with d, most recent last
+	podcastInfo    podcast.PodcastInfo
+	episodes       []podcast.Episode
 	cursor         int
 	offset         int
 	windowHeight   int
+	windowWidth    int
+	previewScroll  int
+	previewText    string // plain-text notes of the previewed episode
+	selectedCount  int    // episodes with Selected set
+	resumed        int    // episodes selected from an interrupted download
+	resumeFile     string // resume file of the running download
 	spinner        spinner.Model
 	progress       progress.Model
 	loadingMsg     string
 	errorMsg       string
+	errorHint      string // suggested next step for errorMsg
 	downloadIndex  int
 	downloadTotal  int
-	outputDir      string
+	paused         bool // start no more downloads until unpaused
+	halted         bool // paused with no download running
+	output         layout
 	baseDir        string
-	downloaded     []string
+	results        []downloadResult // one per finished episode, in download order
+	earlier        []downloadResult // results kept from the passes before a retry
 	percent        float64
-	searchProvider SearchProvider
+	episodeStart   time.Time // when the running episode first reported progress
+	startPercent   float64   // its progress then, above 0 when resuming
+	searchProvider podcast.SearchProvider
+	feedCache      map[string]feedPreview // parsed feeds from previews, keyed by feed URL
+	opts           options
+	theme          theme
+	budget         sizeBudget
+	skipped        []string
+	estimate       spaceEstimate
+	sortBy         podcast.SortKey // current episode order; empty is feed order
+	sortReverse    bool
+	playerErr      string // why the last preview playback failed
+	dirInput       textinput.Model
+	searchInput    textinput.Model
+	numberInput    textinput.Model
+	numberErr      string // why the typed episode numbers can't be used
+	notice         string // one-off status line on the selection screen
+	dirErr         string
+	recent         *recentBrowse // set while browsing --recent episodes
+}
+
+// options holds command-line settings shared by the TUI and batch mode
+type options struct {
+	maxTotalSize  int64 // bytes; 0 means unlimited
+	theme         theme
+	saveFeed      bool            // keep a copy of the feed XML in the podcast folder
+	checksums     bool            // keep a SHA-256 manifest in the podcast folder
+	sortBy        podcast.SortKey // empty keeps the feed's order
+	reverse       bool
+	flatSingle    bool // put a lone episode in the base folder, not a podcast subfolder
+	flat          bool // put every episode in the base folder
+	podcastPrefix bool // start file names with the podcast name
+	tags          podcast.TagOptions
+	renumber      bool   // number the episodes being downloaded 1..N
+	byAuthor      string // keep only search results by this author
+	retagExisting bool   // rewrite the tags of episodes already on disk
+	download      podcast.DownloadOptions
+	newest        bool           // download only the most recent episode
+	trailers      bool           // download only the podcasts' trailers
+	selectRegex   *regexp.Regexp // pre-select matching titles in the picker
+	downloadOrder podcast.DownloadOrder
+	parallelFeeds int             // feeds loaded at once in batch mode
+	retries       int             // passes over a batch's failed episodes at its end
+	prependDate   bool            // start file names with the publish date
+	mergeListings bool            // add episodes from other directory listings of the show
+	embedArtwork  bool            // embed the podcast's cover in MP3 tags
+	cover         bool            // save the podcast's cover as folder.jpg in its folder
+	artworkJPEG   bool            // convert covers other than JPEG and PNG to JPEG
+	includeFuture bool            // keep episodes dated in the future
+	startJitter   time.Duration   // longest random pause between starting concurrent workers
+	languages     []string        // in batch mode, skip feeds in other languages
+	dedup         bool            // collapse episodes the feed lists more than once
+	within        *podcast.Window // keep only episodes published this recently
+	podcasts      podcastConfig   // per-podcast settings from the config file
+	update        bool            // in batch mode, skip feeds unchanged since their last complete download
+	folder        string          // the podcast's folder under the output directory, from its settings
+	columns       []column        // episode details shown beside the titles in the picker
+
+	// started is told as each batch episode starts downloading, for --serve
+	started func(i int, path string)
+}
+
+// layout is where the files of a batch are written
+type layout struct {
+	name       string // sanitized podcast name
+	dir        string // folder the files go in
+	prefix     string // prepended to each file name
+	feedPrefix string // prepended to the saved feed's name
+	datePrefix bool   // put the publish date before each episode's name
+	shared     bool   // dir may hold other podcasts' episodes
+
+	// podcasts places episodes of other podcasts, by audio URL, when
+	// browsing recent episodes
+	podcasts map[string]layout
+}
+
+// newLayout places count episodes of info under baseDir: in the podcast's
+// own folder, or directly in baseDir with --flat, or with
+// --no-subfolder-if-single and a single episode. --podcast-prefix, and
+// --no-subfolder-if-single when it applies, prefix file names with the
+// podcast name.
+func newLayout(info podcast.PodcastInfo, baseDir string, count int, opts options) layout {
+	opts = opts.forPodcast(info)
+	name := podcast.SanitizeFilename(info.Name)
+	single := opts.flatSingle && count == 1
+	l := layout{name: name, dir: filepath.Join(baseDir, name), datePrefix: opts.prependDate}
+	if opts.flat || single {
+		l.dir = baseDir
+		l.feedPrefix = name + " - " // one folder may hold several feeds
+		l.shared = true
+	}
+	if opts.podcastPrefix || single {
+		l.prefix = name + " - "
+		l.feedPrefix = l.prefix
+	}
+	if opts.folder != "" {
+		l.dir = filepath.Join(baseDir, opts.folder)
+		l.shared = false
+	}
+	return l
+}
+
+// episodePath is where ep is saved. With --prepend-date, dated episodes
+// are named like "2023-05-12 - 001 - Title.mp3".
+func (l layout) episodePath(ep podcast.Episode) string {
+	if own, ok := l.podcasts[ep.AudioURL]; ok {
+		return own.episodePath(ep)
+	}
+	prefix := l.prefix
+	if l.datePrefix && !ep.PubDate.IsZero() {
+		prefix += ep.PubDate.Format("2006-01-02") + " - "
+	}
+	return filepath.Join(l.dir, prefix+podcast.EpisodeFilename(l.dir, prefix, ep))
+}
+
+// feedPath is where --save-feed writes the feed
+func (l layout) feedPath() string {
+	return filepath.Join(l.dir, l.feedPrefix+podcast.FeedFilename)
+}
+
+// coverPath is where --cover saves the podcast's cover, or "" when the
+// folder isn't the podcast's own
+func (l layout) coverPath() string {
+	if l.shared || l.podcasts != nil {
+		return ""
+	}
+	return filepath.Join(l.dir, coverFile)
+}
+
+// sizeBudget tracks bytes written in a batch against --max-total-size
+type sizeBudget struct {
+	limit     int64 // 0 means unlimited
+	written   int64
+	exhausted bool
+}
+
+// feedPreview holds a feed fetched while previewing a search result
+type feedPreview struct {
+	feed         *gofeed.Feed
+	data         []byte // the feed as served, kept for --save-feed
+	movedTo      string // new feed URL after a permanent redirect
+	blocked      bool   // the feed sets itunes:block
+	language     string // the feed's <language>, e.g. en-us
+	newFeedURL   string // the feed's itunes:new-feed-url
+	funding      []podcast.Funding
+	value        *podcast.ValueBlock
+	episodeCount int
+	recent       []podcast.Episode // newest first, at most previewRecentCount
+	err          error
 }
 
+// previewRecentCount is how many of the newest episodes the podcast preview lists
+const previewRecentCount = 5
+
 // Messages
 type searchResultsMsg struct {
-	results []SearchResult
+	results []podcast.SearchResult
 }
 
 type podcastLoadedMsg struct {
-	info     PodcastInfo
-	episodes []Episode
+	info     podcast.PodcastInfo
+	episodes []podcast.Episode
+	merged   bool // other listings have been looked for
 }
 
 type errorMsg struct {
 	err error
 }
 
-type downloadProgressMsg float64
+// downloadProgressMsg reports progress of the running download; events
+// delivers its next message
+type downloadProgressMsg struct {
+	percent float64
+	events  <-chan tea.Msg
+}
 
 type downloadCompleteMsg struct {
+	result downloadResult
+	size   int64
+}
+
+// downloadStatus is what happened to an episode the TUI was asked to download
+type downloadStatus int
+
+const (
+	statusDownloaded downloadStatus = iota // written, in full or resumed
+	statusExisted                          // already on disk and kept
+	statusFailed
+)
+
+// downloadResult is the outcome for one episode
+type downloadResult struct {
+	url      string // the episode's audio URL
 	filename string
+	status   downloadStatus
+	err      error  // set when status is statusFailed
+	warning  string // set when the file doesn't look like what the feed announced
+}
+
+type sizeCapReachedMsg struct{}
+
+type spaceEstimateMsg struct {
+	estimate spaceEstimate
 }
 
-type startDownloadMsg struct{}
+// startDownloadMsg starts the downloads, tagging them with tags
+type startDownloadMsg struct {
+	tags       podcast.TagOptions
+	artworkErr error // why the cover couldn't be embedded or saved
+	feedErr    error // why the feed couldn't be saved
+}
 
 type selectSearchResultMsg struct {
-	result SearchResult
+	result podcast.SearchResult
 }
 
-// isNumeric checks if a string is all digits (podcast ID)
-func isNumeric(s string) bool {
-	for _, c := range s {
-		if c < '0' || c > '9' {
-			return false
-		}
-	}
-	return len(s) > 0
+type feedPreviewMsg struct {
+	feedURL string
+	preview feedPreview
 }
 
-func initialModel(input string, baseDir string, provider SearchProvider) model {
+func initialModel(input string, baseDir string, provider podcast.SearchProvider, opts options) model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	s.Style = opts.theme.spinner
 
-	p := progress.New(progress.WithDefaultGradient())
+	p := progress.New(opts.theme.progress...)
 
-	isID := isNumeric(input)
+	isID := podcast.IsID(input)
 
 	m := model{
 		state:          stateLoading,
 		spinner:        s,
 		progress:       p,
 		windowHeight:   24,
+		windowWidth:    80,
 		baseDir:        baseDir,
 		searchProvider: provider,
+		feedCache:      make(map[string]feedPreview),
+		opts:           opts,
+		theme:          opts.theme,
+		dirInput:       newDirInput(opts.theme),
+		searchInput:    newSearchInput(opts.theme),
+		numberInput:    newNumberInput(opts.theme),
+		sortBy:         opts.sortBy,
+		sortReverse:    opts.reverse,
+		budget:         sizeBudget{limit: opts.maxTotalSize},
 	}
 
-	if isID {
+	switch {
+	case input == "":
+		m.state = stateSearchInput
+		m.searchInput.Focus()
+	case isID:
 		m.podcastID = input
 		m.loadingMsg = "Looking up podcast..."
-	} else {
+	case podcast.IsFeedURL(input) || podcast.IsFeedFile(input):
+		m.podcastInfo.FeedURL = input
+		m.loadingMsg = "Loading feed..."
+	default:
 		m.searchQuery = input
-		var providerName string
-		if provider == ProviderPodcastIndex {
-			providerName = "Podcast Index"
-		} else if hasPodcastIndexCredentials() {
-			providerName = "Apple + Podcast Index"
-		} else {
-			providerName = "Apple Podcasts"
-		}
-		m.loadingMsg = fmt.Sprintf("Searching %s...", providerName)
+		m.loadingMsg = searchingMsg(provider)
 	}
 
 	return m
 }
 
+// searchingMsg is the loading message shown while searching with provider
+func searchingMsg(provider podcast.SearchProvider) string {
+	var providerName string
+	if provider == podcast.ProviderPodcastIndex {
+		providerName = "Podcast Index"
+	} else if podcast.HasPodcastIndexCredentials() {
+		providerName = "Apple + Podcast Index"
+	} else {
+		providerName = "Apple Podcasts"
+	}
+	return fmt.Sprintf("Searching %s...", providerName)
+}
+
+// searchCmd searches for query with provider
+func searchCmd(query string, provider podcast.SearchProvider) tea.Cmd {
+	// If credentials are available and no specific provider was forced, search both
+	if podcast.HasPodcastIndexCredentials() && provider == podcast.ProviderApple {
+		return searchBoth(query)
+	} else if provider == podcast.ProviderPodcastIndex {
+		return searchPodcastIndex(query)
+	}
+	return searchPodcasts(query)
+}
+
 func (m model) Init() tea.Cmd {
-	if m.searchQuery != "" {
-		var searchCmd tea.Cmd
-		// If credentials are available and no specific provider was forced, search both
-		if hasPodcastIndexCredentials() && m.searchProvider == ProviderApple {
-			searchCmd = searchBoth(m.searchQuery)
-		} else if m.searchProvider == ProviderPodcastIndex {
-			searchCmd = searchPodcastIndex(m.searchQuery)
-		} else {
-			searchCmd = searchPodcasts(m.searchQuery)
-		}
+	switch {
+	case m.state == stateSearchInput:
+		return m.spinner.Tick
+	case m.recent != nil:
+		return tea.Batch(m.spinner.Tick, loadRecent(0))
+	case m.searchQuery != "":
 		return tea.Batch(
 			m.spinner.Tick,
-			searchCmd,
+			searchCmd(m.searchQuery, m.searchProvider),
+		)
+	case m.podcastInfo.FeedURL != "":
+		return tea.Batch(
+			m.spinner.Tick,
+			loadPodcastFromFeed(m.podcastInfo.FeedURL, "", "", ""),
 		)
 	}
 	return tea.Batch(
@@ -265,7 +497,12 @@ func (m model) Init() tea.Cmd {
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if msg.String() == "?" && hasHelp(m.state) {
+			return m.openHelp()
+		}
 		switch m.state {
+		case stateHelp:
+			return m.handleHelpKeys(msg)
 		case stateSearchResults:
 			return m.handleSearchResultsKeys(msg)
 		case statePreviewPodcast:
@@ -278,28 +515,52 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case stateSelecting:
 			return m.handleSelectionKeys(msg)
+		case stateConfirm:
+			return m.handleConfirmKeys(msg)
 		case statePreviewEpisode:
-			if msg.String() == "esc" || msg.String() == "b" || msg.String() == "v" {
-				m.state = stateSelecting
-				return m, nil
-			}
-			if msg.String() == "ctrl+c" || msg.String() == "q" {
-				return m, tea.Quit
-			}
+			return m.handlePreviewEpisodeKeys(msg)
+		case stateEditDir:
+			return m.handleEditDirKeys(msg)
+		case stateSearchInput:
+			return m.handleSearchInputKeys(msg)
+		case stateSelectNumbers:
+			return m.handleNumberInputKeys(msg)
 		case stateDownloading:
 			if msg.String() == "esc" || msg.String() == "b" {
 				// Go back to episode selection
 				m.state = stateSelecting
 				m.downloadIndex = 0
 				m.downloadTotal = 0
-				m.percent = 0
-				m.downloaded = nil
+				m.percent, m.episodeStart = 0, time.Time{}
+				m.results = nil
+				m.earlier = nil
+				m.skipped = nil
+				m.stopErr = nil
+				m.paused, m.halted = false, false
+				m.budget = sizeBudget{limit: m.opts.maxTotalSize}
 				return m, nil
 			}
+			if msg.String() == "p" {
+				return m.togglePause()
+			}
 			if msg.String() == "ctrl+c" || msg.String() == "q" {
 				return m, tea.Quit
 			}
-		case stateDone, stateError:
+		case stateError:
+			if msg.String() == "/" {
+				return m.openSearchInput()
+			}
+			if msg.String() == "q" || msg.String() == "ctrl+c" || msg.String() == "enter" {
+				return m, tea.Quit
+			}
+		case stateDone:
+			m.notice = ""
+			if msg.String() == "r" && len(m.unfinished()) > 0 {
+				return m.retryUnfinished()
+			}
+			if msg.String() == "o" {
+				return m, openFolder(m.output.dir)
+			}
 			if msg.String() == "q" || msg.String() == "ctrl+c" || msg.String() == "enter" {
 				return m, tea.Quit
 			}
@@ -311,7 +572,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.WindowSizeMsg:
 		m.windowHeight = msg.Height
-		m.progress.Width = msg.Width - 10
+		m.windowWidth = msg.Width
+		m.progress.Width = progressWidth(msg.Width)
 
 	case spinner.TickMsg:
 		var cmd tea.Cmd
@@ -320,9 +582,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case searchResultsMsg:
 		m.searchResults = msg.results
-		if len(msg.results) == 0 {
+		m.hiddenResults = nil
+		if m.opts.byAuthor != "" {
+			m.searchResults = podcast.FilterByAuthor(m.searchResults, m.opts.byAuthor)
+		}
+		if len(m.searchResults) == 0 {
 			m.state = stateError
 			m.errorMsg = fmt.Sprintf("No podcasts found for: %s", m.searchQuery)
+			m.errorHint = errorHint(podcast.ErrNotFound)
+			if m.opts.byAuthor != "" {
+				m.errorMsg += fmt.Sprintf(" by %s", m.opts.byAuthor)
+			}
 			return m, nil
 		}
 		m.state = stateSearchResults
@@ -330,10 +600,31 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.offset = 0
 		return m, nil
 
+	case feedPreviewMsg:
+		m.feedCache[msg.feedURL] = msg.preview
+		return m, nil
+
+	case recentLoadedMsg:
+		return m.addRecent(msg)
+
 	case selectSearchResultMsg:
 		m.state = stateLoading
 		m.loadingMsg = fmt.Sprintf("Loading %s...", msg.result.Name)
-		if msg.result.Source == ProviderPodcastIndex {
+		// Reuse the feed parsed while previewing, if any
+		if cached, ok := m.feedCache[msg.result.FeedURL]; ok && cached.feed != nil {
+			m.podcastID = msg.result.ID
+			info := podcast.PodcastInfo{
+				Name:       msg.result.Name,
+				Artist:     msg.result.Artist,
+				FeedURL:    msg.result.FeedURL,
+				ArtworkURL: msg.result.ArtworkURL,
+				ID:         msg.result.ID,
+			}
+			info.FeedMoved(cached.movedTo)
+			info.Feed = cached.data
+			return m, func() tea.Msg { return podcastLoaded(podcast.FromFeed(cached.feed, info)) }
+		}
+		if msg.result.Source == podcast.ProviderPodcastIndex {
 			// Load directly from RSS feed URL for Podcast Index results
 			return m, loadPodcastFromFeed(msg.result.FeedURL, msg.result.Name, msg.result.Artist, msg.result.ArtworkURL)
 		}
@@ -341,50 +632,190 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, loadPodcast(msg.result.ID)
 
 	case podcastLoadedMsg:
+		if m.opts.mergeListings && !msg.merged {
+			m.loadingMsg = "Looking for other listings of the show..."
+			return m, mergeListings(msg.info, msg.episodes)
+		}
 		m.state = stateSelecting
+		previous, before := m.podcastInfo, m.episodes
 		m.podcastInfo = msg.info
 		m.episodes = msg.episodes
+		m.futureHidden = 0
+		if !m.opts.includeFuture {
+			m.episodes, m.futureHidden = podcast.DropFuture(m.episodes, time.Now())
+		}
+		m.olderHidden = 0
+		if m.opts.within != nil {
+			m.episodes, m.olderHidden = podcast.DropBefore(m.episodes, m.opts.within.Cutoff(time.Now()))
+		}
+		m.duplicates = 0
+		if m.opts.dedup {
+			m.episodes, m.duplicates = podcast.DedupEpisodes(m.episodes)
+		}
+		// Checked once every filter has run, so none can leave an empty list
+		if err := hiddenAll(len(m.episodes), m.futureHidden, m.olderHidden, m.opts.within); err != nil {
+			m.state = stateError
+			m.errorMsg, m.errorHint = err.Error(), ""
+			return m, nil
+		}
 		m.cursor = 0
 		m.offset = 0
+		if m.sortBy != "" {
+			podcast.SortEpisodes(m.episodes, m.sortBy, m.sortReverse)
+		}
+		m.selectedCount = 0
+		if m.opts.selectRegex != nil {
+			for i := range m.episodes {
+				m.episodes[i].Selected = m.opts.selectRegex.MatchString(m.episodes[i].Title)
+				if m.episodes[i].Selected {
+					m.selectedCount++
+				}
+			}
+		}
+		// Offer to finish an interrupted download by selecting what it left
+		m.resumed = 0
+		if pending, _ := findResume(m.podcastInfo, m.baseDir, m.episodes, m.opts); len(pending) > 0 {
+			urls := make(map[string]bool)
+			for _, ep := range pending {
+				urls[ep.AudioURL] = true
+			}
+			for i := range m.episodes {
+				m.episodes[i].Selected = urls[m.episodes[i].AudioURL]
+			}
+			m.selectedCount = len(pending)
+			m.resumed = len(pending)
+			// A partial file must be completed, not skipped
+			if m.opts.download.OnExists == "" || m.opts.download.OnExists == podcast.ExistsSkip {
+				m.opts.download.OnExists = podcast.ExistsCheck
+			}
+		}
+		// Reloading the show from its other listing or its new feed keeps
+		// the episodes picked so far
+		if m.resumed == 0 && podcast.SameShow(previous, podcast.SearchResult{Name: msg.info.Name, Artist: msg.info.Artist}) {
+			if carried := podcast.CarrySelection(m.episodes, before); carried > 0 {
+				m.selectedCount = 0
+				for _, ep := range m.episodes {
+					if ep.Selected {
+						m.selectedCount++
+					}
+				}
+				m.notice = fmt.Sprintf("Kept the selection of %d episode(s) from the show's previous listing", carried)
+			}
+		}
 		return m, nil
 
 	case errorMsg:
 		m.state = stateError
 		m.errorMsg = msg.err.Error()
+		m.errorHint = errorHint(msg.err)
 		return m, nil
 
 	case downloadProgressMsg:
-		m.percent = float64(msg)
+		if m.episodeStart.IsZero() {
+			m.episodeStart, m.startPercent = time.Now(), msg.percent
+		}
+		m.percent = msg.percent
 		cmd := m.progress.SetPercent(m.percent)
-		return m, cmd
+		return m, tea.Batch(cmd, waitForDownload(msg.events))
 
 	case progress.FrameMsg:
 		progressModel, cmd := m.progress.Update(msg)
 		m.progress = progressModel.(progress.Model)
 		return m, cmd
 
+	case folderOpenedMsg:
+		switch {
+		case errors.Is(msg.err, errNoFileManager):
+			m.notice = "Files are in " + msg.dir
+		case msg.err != nil:
+			m.notice = fmt.Sprintf("Couldn't open %s: %v", msg.dir, msg.err)
+		default:
+			m.notice = "Opened " + msg.dir
+		}
+		return m, nil
+
+	case playerExitedMsg:
+		if msg.err != nil {
+			m.playerErr = msg.err.Error()
+		}
+		return m, nil
+
 	case startDownloadMsg:
+		m.opts.tags = msg.tags
+		m.artworkErr = msg.artworkErr
+		m.feedErr = msg.feedErr
 		return m, m.downloadNextCmd()
 
+	case spaceEstimateMsg:
+		m.estimate = msg.estimate
+		m.state = stateConfirm
+		return m, nil
+
+	case sizeCapReachedMsg:
+		// Skip everything not yet started
+		for _, ep := range m.getSelectedEpisodes()[m.downloadIndex:] {
+			m.skipped = append(m.skipped, filepath.Base(m.output.episodePath(ep)))
+		}
+		saveResume(m.resumeFile, m.podcastInfo, m.pendingEpisodes())
+		return m.finishDownloads(), nil
+
 	case downloadCompleteMsg:
-		m.downloaded = append(m.downloaded, msg.filename)
+		m.results = append(m.results, msg.result)
+		saveResume(m.resumeFile, m.podcastInfo, m.pendingEpisodes())
+		m.budget.written += msg.size
 		m.downloadIndex++
-		m.percent = 0
+		m.percent, m.episodeStart = 0, time.Time{}
+		if msg.result.status == statusFailed && fatalDownloadError(msg.result.err) {
+			// Every later episode would fail the same way; keep what is done
+			m.stopErr = msg.result.err
+			return m.finishDownloads(), nil
+		}
 		if m.downloadIndex < m.downloadTotal {
+			if m.paused {
+				m.halted = true
+				return m, nil
+			}
 			return m, m.downloadNextCmd()
 		}
-		m.state = stateDone
-		return m, nil
+		return m.finishDownloads(), nil
 	}
 
 	return m, nil
 }
 
+// togglePause pauses the downloads once the running one finishes, or
+// resumes them with the next pending episode
+func (m model) togglePause() (tea.Model, tea.Cmd) {
+	if !m.paused {
+		m.paused = true
+		return m, nil
+	}
+	m.paused = false
+	if m.halted {
+		m.halted = false
+		m.percent, m.episodeStart = 0, time.Time{}
+		return m, m.downloadNextCmd()
+	}
+	return m, nil
+}
+
+// finishDownloads moves to the done screen, or puts it under the help
+// overlay if that is open
+func (m model) finishDownloads() model {
+	if m.state == stateHelp {
+		m.helpReturn = stateDone
+	} else {
+		m.state = stateDone
+	}
+	return m
+}
+
 func (m model) handleSearchResultsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	visibleItems := m.windowHeight - 10
 	if visibleItems < 5 {
 		visibleItems = 5
 	}
+	m.notice = ""
 
 	switch msg.String() {
 	case "ctrl+c", "q":
@@ -412,9 +843,28 @@ func (m model) handleSearchResultsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, func() tea.Msg { return selectSearchResultMsg{result: result} }
 		}
 
+	case "/":
+		return m.openSearchInput()
+
+	case "d":
+		return m.hideResult(visibleItems), nil
+
+	case "u":
+		return m.unhideResult(visibleItems), nil
+
+	case "c":
+		return m.copyFeeds(false), nil
+
+	case "C":
+		return m.copyFeeds(true), nil
+
 	case "v":
 		if m.cursor < len(m.searchResults) {
 			m.state = statePreviewPodcast
+			feedURL := m.searchResults[m.cursor].FeedURL
+			if _, ok := m.feedCache[feedURL]; !ok && feedURL != "" {
+				return m, fetchFeedPreview(feedURL)
+			}
 			return m, nil
 		}
 	}
@@ -427,6 +877,7 @@ func (m model) handleSelectionKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if visibleItems < 5 {
 		visibleItems = 5
 	}
+	m.notice = ""
 
 	switch msg.String() {
 	case "ctrl+c", "q":
@@ -476,35 +927,95 @@ func (m model) handleSelectionKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case " ", "x":
+		if m.cursor >= len(m.episodes) {
+			break
+		}
 		m.episodes[m.cursor].Selected = !m.episodes[m.cursor].Selected
+		if m.episodes[m.cursor].Selected {
+			m.selectedCount++
+		} else {
+			m.selectedCount--
+		}
 
 	case "a":
-		allSelected := true
-		for _, ep := range m.episodes {
-			if !ep.Selected {
-				allSelected = false
-				break
-			}
-		}
+		allSelected := m.selectedCount == len(m.episodes)
 		for i := range m.episodes {
 			m.episodes[i].Selected = !allSelected
 		}
+		m.selectedCount = 0
+		if !allSelected {
+			m.selectedCount = len(m.episodes)
+		}
+
+	case "i":
+		for i := range m.episodes {
+			m.episodes[i].Selected = !m.episodes[i].Selected
+		}
+		m.selectedCount = len(m.episodes) - m.selectedCount
+
+	case "#":
+		return m.openNumberInput()
+
+	case "m":
+		return m.loadMoreRecent()
+
+	case "n":
+		if m.podcastInfo.NewFeedURL != "" {
+			m.state = stateLoading
+			m.loadingMsg = fmt.Sprintf("Loading %s...", m.podcastInfo.NewFeedURL)
+			return m, followNewFeedURL(m.podcastInfo)
+		}
+
+	case "o":
+		m.state = stateEditDir
+		m.dirInput.SetValue(m.baseDir)
+		m.dirInput.CursorEnd()
+		return m, m.dirInput.Focus()
+
+	case "s":
+		// Cycle through the orderings
+		next := podcast.SortKeys[0]
+		for i, key := range podcast.SortKeys {
+			if key == m.sortBy {
+				next = podcast.SortKeys[(i+1)%len(podcast.SortKeys)]
+			}
+		}
+		m.sortBy = next
+		m = m.resort(visibleItems)
+
+	case "r":
+		m.sortReverse = !m.sortReverse
+		if m.sortBy == "" {
+			m.sortBy = podcast.SortDate
+		}
+		m = m.resort(visibleItems)
+
+	case "c":
+		m.opts.columns = nextColumns(m.opts.columns)
+		m.notice = "Columns: " + columnsString(m.opts.columns)
+		if err := saveSettings(settings{Columns: columnsString(m.opts.columns)}); err != nil {
+			m.notice += fmt.Sprintf(" (couldn't save: %v)", err)
+		}
 
 	case "enter":
 		selected := m.getSelectedEpisodes()
 		if len(selected) > 0 {
-			m.state = stateDownloading
-			m.downloadTotal = len(selected)
-			m.downloadIndex = 0
-			podcastFolder := sanitizeFilename(m.podcastInfo.Name)
-			m.outputDir = filepath.Join(m.baseDir, podcastFolder)
-			os.MkdirAll(m.outputDir, 0755)
-			return m, func() tea.Msg { return startDownloadMsg{} }
+			m.output = newLayout(m.podcastInfo, m.baseDir, len(selected), m.opts)
+			if m.recent != nil {
+				m.output = m.recent.layout(m.baseDir, m.opts)
+			}
+			m.state = stateLoading
+			m.loadingMsg = "Estimating download size..."
+			output, jitter := m.output, m.opts.startJitter
+			return m, func() tea.Msg {
+				return spaceEstimateMsg{estimate: estimateSpace(output, selected, jitter)}
+			}
 		}
 
 	case "v":
 		if m.cursor < len(m.episodes) {
 			m.state = statePreviewEpisode
+			m.previewText = m.episodes[m.cursor].PlainDescription()
 			return m, nil
 		}
 	}
@@ -512,13 +1023,124 @@ func (m model) handleSelectionKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m model) getSelectedEpisodes() []Episode {
-	var selected []Episode
+// resort puts the episodes in the current order, keeping the cursor on the
+// episode it was on and scrolling it into view. Selections live on the
+// episodes, so they move with them.
+func (m model) resort(visibleItems int) model {
+	var current string
+	if m.cursor < len(m.episodes) {
+		current = m.episodes[m.cursor].AudioURL
+	}
+	podcast.SortEpisodes(m.episodes, m.sortBy, m.sortReverse)
+
+	m.cursor, m.offset = 0, 0
+	for i, ep := range m.episodes {
+		if ep.AudioURL == current {
+			m.cursor = i
+			break
+		}
+	}
+	if m.cursor >= visibleItems {
+		m.offset = m.cursor - visibleItems + 1
+	}
+	return m
+}
+
+func (m model) handleConfirmKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+
+	case "esc", "b", "n":
+		m.state = stateSelecting
+		return m, nil
+
+	case "enter", "y":
+		m.state = stateDownloading
+		m.downloadTotal = len(m.getSelectedEpisodes())
+		m.downloadIndex = 0
+		m.resumed = 0
+		os.MkdirAll(m.output.dir, 0755)
+		m.resumeFile = m.output.resumePath()
+		saveResume(m.resumeFile, m.podcastInfo, m.getSelectedEpisodes())
+		info, feedPath, coverPath, opts := m.podcastInfo, m.output.feedPath(), m.output.coverPath(), m.opts
+		return m, func() tea.Msg {
+			// Neither the feed copy nor the cover is worth stopping the
+			// downloads for; the done screen reports them
+			var feedErr error
+			if opts.saveFeed && info.FeedURL != "" {
+				feedErr = podcast.SaveFeed(info, feedPath)
+			}
+			tags, err := withArtwork(opts.tags, info, coverPath, opts)
+			return startDownloadMsg{tags: tags, artworkErr: err, feedErr: feedErr}
+		}
+	}
+
+	return m, nil
+}
+
+func (m model) handlePreviewEpisodeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxScroll := max(len(m.previewDescriptionLines())-m.previewVisibleLines(), 0)
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+
+	case "esc", "b", "v":
+		m.state = stateSelecting
+		m.previewScroll = 0
+		m.playerErr = ""
+
+	case "p":
+		m.playerErr = ""
+		return m, playEpisode(m.episodes[m.cursor].AudioURL)
+
+	case "up", "k":
+		m.previewScroll--
+
+	case "down", "j":
+		m.previewScroll++
+
+	case "pgup":
+		m.previewScroll -= m.previewVisibleLines()
+
+	case "pgdown":
+		m.previewScroll += m.previewVisibleLines()
+	}
+
+	m.previewScroll = min(max(m.previewScroll, 0), maxScroll)
+	return m, nil
+}
+
+// pendingEpisodes are the selected episodes the running download hasn't
+// finished: those not reached yet and those that failed
+func (m model) pendingEpisodes() []podcast.Episode {
+	failed := make(map[string]bool)
+	for _, r := range m.results {
+		if r.status == statusFailed {
+			failed[r.url] = true
+		}
+	}
+	var pending []podcast.Episode
+	for i, ep := range m.getSelectedEpisodes() {
+		if i >= len(m.results) || failed[ep.AudioURL] {
+			pending = append(pending, ep)
+		}
+	}
+	return pending
+}
+
+func (m model) getSelectedEpisodes() []podcast.Episode {
+	var selected []podcast.Episode
 	for _, ep := range m.episodes {
 		if ep.Selected {
 			selected = append(selected, ep)
 		}
 	}
+	if m.opts.renumber {
+		podcast.Renumber(selected)
+	}
+	podcast.OrderForDownload(selected, m.opts.downloadOrder)
 	return selected
 }
 
@@ -529,23 +1151,79 @@ func (m model) downloadNextCmd() tea.Cmd {
 	}
 
 	ep := selected[m.downloadIndex]
-	currentFile := fmt.Sprintf("%03d - %s.mp3", ep.Index, sanitizeFilename(ep.Title))
-	outputDir := m.outputDir
+	filePath := m.output.episodePath(ep)
 	podcastInfo := m.podcastInfo
-
-	return func() tea.Msg {
-		filePath := filepath.Join(outputDir, currentFile)
-
-		// Download with progress callback that sends to program
-		err := downloadFileWithProgress(filePath, ep.AudioURL)
+	if m.recent != nil {
+		podcastInfo = m.recent.podcasts[ep.AudioURL]
+	}
+	tagOpts, retagExisting := m.opts.tags, m.opts.retagExisting
+	dl := m.opts.forPodcast(podcastInfo).download
+	checksums := m.opts.checksums
+	if checksums {
+		dl.Hash = sha256.New()
+	}
+	budget := m.budget
+
+	// The download runs in its own goroutine and reports progress and its
+	// final result through events, which waitForDownload turns into messages
+	events := make(chan tea.Msg, 1)
+	go func() {
+		if !budget.admits(filePath, ep) {
+			events <- sizeCapReachedMsg{}
+			return
+		}
+
+		_, statErr := os.Stat(filePath)
+		existed := statErr == nil
+		// Recent episodes each go to their own podcast's folder
+		os.MkdirAll(filepath.Dir(filePath), 0755)
+
+		dl.OnProgress = func(percent float64) {
+			// Drop updates the UI hasn't caught up with rather than stall the download
+			select {
+			case events <- downloadProgressMsg{percent: percent, events: events}:
+			default:
+			}
+		}
+		size, err := podcast.Download(filePath, ep.AudioURL, dl)
 		if err != nil {
-			return errorMsg{err: err}
+			// Carry on with the rest; the done screen lists the failures
+			events <- downloadCompleteMsg{result: downloadResult{url: ep.AudioURL, filename: filePath, status: statusFailed, err: err}, size: size}
+			return
+		}
+		existed = existed && size == 0 // resumed or downloaded again under --on-exists
+
+		var warning string
+		// Files already present are only re-tagged with --overwrite-tags-only
+		if !existed {
+			warning = sizeWarning(ep, filePath)
+			podcast.FillDuration(&ep, filePath)
+			podcast.AddID3Tags(filePath, ep, podcastInfo, tagOpts)
+			recordDownload(podcastInfo, ep, filePath) // history is best-effort
+			if checksums {
+				if err := recordChecksum(filePath, dl.Hash.Sum(nil)); err != nil && warning == "" {
+					warning = fmt.Sprintf("checksum not recorded: %v", err)
+				}
+			}
+		} else if retagExisting {
+			podcast.AddID3Tags(filePath, ep, podcastInfo, tagOpts)
+			refreshChecksum(filePath)
+		}
+
+		status := statusDownloaded
+		if existed {
+			status = statusExisted
 		}
+		events <- downloadCompleteMsg{result: downloadResult{url: ep.AudioURL, filename: filePath, status: status, warning: warning}, size: size}
+	}()
 
-		// Add ID3 tags
-		addID3Tags(filePath, ep, podcastInfo)
+	return waitForDownload(events)
+}
 
-		return downloadCompleteMsg{filename: filePath}
+// waitForDownload waits for the next message from a running download
+func waitForDownload(events <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-events
 	}
 }
 
@@ -561,12 +1239,22 @@ func (m model) View() string {
 		return m.viewSelecting()
 	case statePreviewEpisode:
 		return m.viewPreviewEpisode()
+	case stateEditDir:
+		return m.viewEditDir()
+	case stateSearchInput:
+		return m.viewSearchInput()
+	case stateConfirm:
+		return m.viewConfirm()
 	case stateDownloading:
 		return m.viewDownloading()
 	case stateDone:
 		return m.viewDone()
 	case stateError:
 		return m.viewError()
+	case stateHelp:
+		return m.viewHelp()
+	case stateSelectNumbers:
+		return m.viewNumberInput()
 	}
 	return ""
 }
@@ -580,9 +1268,16 @@ func (m model) viewSearchResults() string {
 
 	// Header
 	b.WriteString("\n")
-	b.WriteString(titleStyle.Render(fmt.Sprintf("Search Results: \"%s\"", m.searchQuery)))
+	b.WriteString(m.theme.title.Render(fmt.Sprintf("Search Results: \"%s\"", m.searchQuery)))
 	b.WriteString("\n")
-	b.WriteString(subtitleStyle.Render(fmt.Sprintf("Found %d podcasts", len(m.searchResults))))
+	found := fmt.Sprintf("Found %d podcasts", len(m.searchResults))
+	if m.opts.byAuthor != "" {
+		found += fmt.Sprintf(" by %s", m.opts.byAuthor)
+	}
+	if len(m.hiddenResults) > 0 {
+		found += fmt.Sprintf(" (%d hidden, u to restore)", len(m.hiddenResults))
+	}
+	b.WriteString(m.theme.subtitle.Render(found))
 	b.WriteString("\n\n")
 
 	// Calculate visible items
@@ -597,6 +1292,11 @@ func (m model) viewSearchResults() string {
 		end = len(m.searchResults)
 	}
 
+	// Name and artist share the window two to one
+	avail := m.windowWidth - 6
+	nameWidth := max(avail*2/3, 20)
+	artistWidth := max(avail-nameWidth, 10)
+
 	for i := m.offset; i < end; i++ {
 		result := m.searchResults[i]
 		cursor := "  "
@@ -604,35 +1304,29 @@ func (m model) viewSearchResults() string {
 			cursor = "▸ "
 		}
 
-		// Truncate name
-		name := result.Name
-		if len(name) > 50 {
-			name = name[:47] + "..."
-		}
-
-		// Truncate artist
-		artist := result.Artist
-		if len(artist) > 25 {
-			artist = artist[:22] + "..."
-		}
+		name := truncate(result.Name, nameWidth)
+		artist := truncate(result.Artist, artistWidth)
 
-		line := fmt.Sprintf("%s%-50s  %s", cursor, name, dimStyle.Render(artist))
+		line := fmt.Sprintf("%s%s  %s", cursor, padRight(name, nameWidth), m.theme.dim.Render(artist))
 
 		if i == m.cursor {
-			b.WriteString(selectedStyle.Render(line))
+			b.WriteString(m.theme.selected.Render(line))
 		} else {
-			b.WriteString(normalStyle.Render(line))
+			b.WriteString(m.theme.normal.Render(line))
 		}
 		b.WriteString("\n")
 	}
 
 	// Scroll indicator
 	if len(m.searchResults) > visibleItems {
-		b.WriteString(dimStyle.Render(fmt.Sprintf("\n  Showing %d-%d of %d", m.offset+1, end, len(m.searchResults))))
+		b.WriteString(m.theme.dim.Render(fmt.Sprintf("\n  Showing %d-%d of %d", m.offset+1, end, len(m.searchResults))))
+	}
+	if m.notice != "" {
+		b.WriteString("\n  " + m.theme.success.Render(m.notice))
 	}
 
 	// Help
-	b.WriteString(helpStyle.Render("\n\n  ↑/↓ navigate • enter select • v preview • q quit"))
+	b.WriteString(m.theme.help.Render("\n\n  ↑/↓ navigate • enter select • v preview • d hide • c copy feeds • / new search • ? help • q quit"))
 
 	return b.String()
 }
@@ -646,35 +1340,110 @@ func (m model) viewPreviewPodcast() string {
 	result := m.searchResults[m.cursor]
 
 	b.WriteString("\n")
-	b.WriteString(titleStyle.Render("Podcast Details"))
+	b.WriteString(m.theme.title.Render("Podcast Details"))
 	b.WriteString("\n\n")
 
-	b.WriteString(fmt.Sprintf("  %s %s\n", subtitleStyle.Render("Name:"), result.Name))
-	b.WriteString(fmt.Sprintf("  %s %s\n", subtitleStyle.Render("Artist:"), result.Artist))
-	b.WriteString(fmt.Sprintf("  %s %s\n", subtitleStyle.Render("Source:"), string(result.Source)))
+	b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Name:"), result.Name))
+	b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Artist:"), result.Artist))
+	b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Source:"), string(result.Source)))
 	if result.ID != "" {
-		b.WriteString(fmt.Sprintf("  %s %s\n", subtitleStyle.Render("ID:"), result.ID))
+		b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("ID:"), result.ID))
 	}
 	if result.FeedURL != "" {
-		b.WriteString(fmt.Sprintf("  %s %s\n", subtitleStyle.Render("Feed URL:"), result.FeedURL))
+		b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Feed URL:"), result.FeedURL))
 	}
 	if result.ArtworkURL != "" {
-		b.WriteString(fmt.Sprintf("  %s %s\n", subtitleStyle.Render("Artwork:"), result.ArtworkURL))
+		b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Artwork:"), result.ArtworkURL))
 	}
 
-	b.WriteString(helpStyle.Render("\n\n  esc/b/v back • q quit"))
-
-	return b.String()
-}
-
-func (m model) viewSelecting() string {
-	var b strings.Builder
-
-	// Header
+	// Feed details, fetched in the background
 	b.WriteString("\n")
-	b.WriteString(titleStyle.Render(m.podcastInfo.Name))
+	if preview, ok := m.feedCache[result.FeedURL]; !ok {
+		b.WriteString(fmt.Sprintf("  %s Fetching feed details...\n", m.spinner.View()))
+	} else if preview.err != nil {
+		b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Feed:"), m.theme.dim.Render("unavailable ("+preview.err.Error()+")")))
+	} else {
+		b.WriteString(fmt.Sprintf("  %s %d\n", m.theme.subtitle.Render("Episodes:"), preview.episodeCount))
+		if preview.language != "" {
+			b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Language:"), preview.language))
+		}
+		if preview.newFeedURL != "" {
+			b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Moved to:"), m.theme.error.Render(preview.newFeedURL)))
+		}
+		for _, f := range preview.funding {
+			b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Support:"), fundingLine(f)))
+		}
+		if preview.value != nil {
+			b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Value4Value:"), preview.value.Describe()))
+		}
+		if len(preview.recent) > 0 && !preview.recent[0].PubDate.IsZero() {
+			b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Latest:"), preview.recent[0].PubDate.Format("January 2, 2006")))
+		}
+		if len(preview.recent) > 0 {
+			b.WriteString(fmt.Sprintf("\n  %s\n", m.theme.subtitle.Render("Recent episodes:")))
+			for _, ep := range preview.recent {
+				dateStr := "          "
+				if !ep.PubDate.IsZero() {
+					dateStr = ep.PubDate.Format("2006-01-02")
+				}
+				title := truncate(ep.Title, max(m.windowWidth-20, 20))
+				b.WriteString(fmt.Sprintf("    %s  %s\n", m.theme.dim.Render(dateStr), title))
+			}
+		}
+		if preview.blocked {
+			b.WriteString("\n  " + m.theme.error.Render("The publisher marked this feed itunes:block (not meant for redistribution)") + "\n")
+		}
+	}
+
+	b.WriteString(m.theme.help.Render("\n\n  esc/b/v back • ? help • q quit"))
+
+	return b.String()
+}
+
+func (m model) viewSelecting() string {
+	var b strings.Builder
+
+	// Header
 	b.WriteString("\n")
-	b.WriteString(subtitleStyle.Render(fmt.Sprintf("by %s • %d episodes", m.podcastInfo.Artist, len(m.episodes))))
+	b.WriteString(m.theme.title.Render(m.podcastInfo.Name))
+	b.WriteString("\n")
+	subtitle := fmt.Sprintf("by %s • %d episodes", m.podcastInfo.Artist, len(m.episodes))
+	if m.futureHidden > 0 {
+		subtitle += fmt.Sprintf(" (%d future-dated hidden)", m.futureHidden)
+	}
+	if m.olderHidden > 0 {
+		subtitle += fmt.Sprintf(" (%d older than %s hidden)", m.olderHidden, m.opts.within)
+	}
+	if m.duplicates > 0 {
+		subtitle += fmt.Sprintf(" (%d duplicates collapsed)", m.duplicates)
+	}
+	if m.sortBy != "" {
+		subtitle += fmt.Sprintf(" • sorted by %s", m.sortBy)
+		if m.sortReverse {
+			subtitle += " (reversed)"
+		}
+	}
+	b.WriteString(m.theme.subtitle.Render(subtitle))
+	if m.podcastInfo.MovedFrom != "" {
+		b.WriteString("\n")
+		b.WriteString(m.theme.error.Render(fmt.Sprintf("Feed moved permanently: %s → %s", m.podcastInfo.MovedFrom, m.podcastInfo.FeedURL)))
+	}
+	if m.podcastInfo.NewFeedURL != "" {
+		b.WriteString("\n")
+		b.WriteString(m.theme.error.Render(fmt.Sprintf("The publisher moved this feed to %s, press n to load it", m.podcastInfo.NewFeedURL)))
+	}
+	if len(m.podcastInfo.MergedFrom) > 0 {
+		b.WriteString("\n")
+		b.WriteString(m.theme.dim.Render("Merged with episodes from " + strings.Join(m.podcastInfo.MergedFrom, ", ")))
+	}
+	if warning := blockWarning(m.podcastInfo, m.episodes); warning != "" {
+		b.WriteString("\n")
+		b.WriteString(m.theme.error.Render(warning))
+	}
+	if m.resumed > 0 {
+		b.WriteString("\n")
+		b.WriteString(m.theme.success.Render(fmt.Sprintf("An earlier download was interrupted: its %d unfinished episode(s) are selected, press enter to resume", m.resumed)))
+	}
 	b.WriteString("\n\n")
 
 	// Calculate visible items
@@ -689,6 +1458,17 @@ func (m model) viewSelecting() string {
 		end = len(m.episodes)
 	}
 
+	// The number goes before the title and the other columns after it; the
+	// title gets what they leave
+	widths := columnWidths(m.opts.columns, m.episodes)
+	used := 8 // cursor, checkbox and margins
+	for _, c := range m.opts.columns {
+		if widths[c] > 0 {
+			used += widths[c] + 2
+		}
+	}
+	titleWidth := max(m.windowWidth-used, 20)
+
 	for i := m.offset; i < end; i++ {
 		ep := m.episodes[i]
 		cursor := "  "
@@ -701,53 +1481,57 @@ func (m model) viewSelecting() string {
 			checkbox = "●"
 		}
 
-		// Format date
-		dateStr := ""
-		if !ep.PubDate.IsZero() {
-			dateStr = ep.PubDate.Format("2006-01-02")
-		}
-
-		// Truncate title
 		title := ep.Title
-		if len(title) > 45 {
-			title = title[:42] + "..."
+		if m.recent != nil {
+			title = m.recent.podcasts[ep.AudioURL].Name + ": " + title
 		}
+		title = truncate(title, titleWidth)
 
-		line := fmt.Sprintf("%s%s [%3d] %-45s %s  %s",
-			cursor,
-			checkboxStyle.Render(checkbox),
-			ep.Index,
-			title,
-			dimStyle.Render(dateStr),
-			dimStyle.Render(ep.Duration),
-		)
+		line := cursor + m.theme.checkbox.Render(checkbox) + " "
+		if w := widths[colIndex]; w > 0 {
+			line += fmt.Sprintf("[%*d] ", w, ep.Index)
+		}
+		line += padRight(title, titleWidth)
+		for _, c := range m.opts.columns {
+			cell := columnCell(c, ep)
+			switch {
+			case c == colIndex || widths[c] == 0:
+				continue
+			case c == colDuration || c == colSize:
+				cell = fmt.Sprintf("%*s", widths[c], cell)
+			default:
+				cell = padRight(cell, widths[c])
+			}
+			line += "  " + m.theme.dim.Render(cell)
+		}
 
 		if i == m.cursor {
-			b.WriteString(selectedStyle.Render(line))
+			b.WriteString(m.theme.selected.Render(line))
 		} else if ep.Selected {
-			b.WriteString(normalStyle.Render(line))
+			b.WriteString(m.theme.normal.Render(line))
 		} else {
-			b.WriteString(dimStyle.Render(line))
+			b.WriteString(m.theme.dim.Render(line))
 		}
 		b.WriteString("\n")
 	}
 
 	// Scroll indicator
 	if len(m.episodes) > visibleItems {
-		b.WriteString(dimStyle.Render(fmt.Sprintf("\n  Showing %d-%d of %d", m.offset+1, end, len(m.episodes))))
+		b.WriteString(m.theme.dim.Render(fmt.Sprintf("\n  Showing %d-%d of %d", m.offset+1, end, len(m.episodes))))
 	}
 
 	// Selection count
-	selectedCount := 0
-	for _, ep := range m.episodes {
-		if ep.Selected {
-			selectedCount++
-		}
+	b.WriteString(m.theme.dim.Render(fmt.Sprintf("  •  %d selected  •  saving to %s", m.selectedCount, m.baseDir)))
+	if m.notice != "" {
+		b.WriteString("\n  " + m.theme.success.Render(m.notice))
 	}
-	b.WriteString(dimStyle.Render(fmt.Sprintf("  •  %d selected", selectedCount)))
 
 	// Help
-	b.WriteString(helpStyle.Render("\n\n  ↑/↓ navigate • space select • a toggle all • v preview • enter download • esc/b back • q quit"))
+	more := ""
+	if m.recent != nil {
+		more = " • m more"
+	}
+	b.WriteString(m.theme.help.Render("\n\n  ↑/↓ navigate • space select • a toggle all • i invert • # by number • s sort • r reverse • c columns • v preview • o output dir" + more + " • enter download • esc/b back • ? help • q quit"))
 
 	return b.String()
 }
@@ -758,325 +1542,530 @@ func (m model) viewPreviewEpisode() string {
 	if m.cursor >= len(m.episodes) {
 		return ""
 	}
+
+	b.WriteString(m.previewEpisodeHeader())
+
+	// Description, word-wrapped to the window and scrolled
+	lines := m.previewDescriptionLines()
+	if len(lines) > 0 {
+		visible := m.previewVisibleLines()
+		end := min(m.previewScroll+visible, len(lines))
+		for _, line := range lines[m.previewScroll:end] {
+			b.WriteString("  " + line + "\n")
+		}
+		if len(lines) > visible {
+			b.WriteString(m.theme.dim.Render(fmt.Sprintf("\n  Lines %d-%d of %d", m.previewScroll+1, end, len(lines))))
+		}
+	}
+
+	if m.playerErr != "" {
+		b.WriteString("\n\n  " + m.theme.error.Render("Playback failed: "+m.playerErr))
+	}
+
+	b.WriteString(m.theme.help.Render("\n\n  ↑/↓ scroll • p play • esc/b/v back • ? help • q quit"))
+
+	return b.String()
+}
+
+// previewEpisodeHeader renders the metadata above the episode description
+func (m model) previewEpisodeHeader() string {
+	var b strings.Builder
 	ep := m.episodes[m.cursor]
 
 	b.WriteString("\n")
-	b.WriteString(titleStyle.Render("Episode Details"))
+	b.WriteString(m.theme.title.Render("Episode Details"))
 	b.WriteString("\n\n")
 
-	b.WriteString(fmt.Sprintf("  %s %s\n", subtitleStyle.Render("Title:"), ep.Title))
-	b.WriteString(fmt.Sprintf("  %s %d\n", subtitleStyle.Render("Episode #:"), ep.Index))
+	b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Title:"), ep.Title))
+	b.WriteString(fmt.Sprintf("  %s %d\n", m.theme.subtitle.Render("Episode #:"), ep.Index))
 	if !ep.PubDate.IsZero() {
-		b.WriteString(fmt.Sprintf("  %s %s\n", subtitleStyle.Render("Published:"), ep.PubDate.Format("January 2, 2006")))
+		b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Published:"), ep.PubDate.Format("January 2, 2006")))
 	}
 	if ep.Duration != "" {
-		b.WriteString(fmt.Sprintf("  %s %s\n", subtitleStyle.Render("Duration:"), ep.Duration))
+		b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Duration:"), ep.Duration))
+	}
+	if hosts := ep.PeopleWithRole("host"); len(hosts) > 0 {
+		b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Hosts:"), strings.Join(hosts, ", ")))
+	}
+	if guests := ep.PeopleWithRole("guest"); len(guests) > 0 {
+		b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Guests:"), strings.Join(guests, ", ")))
 	}
 	if ep.AudioURL != "" {
-		b.WriteString(fmt.Sprintf("  %s %s\n", subtitleStyle.Render("Audio URL:"), ep.AudioURL))
-	}
-
-	// Description with word wrap
-	if ep.Description != "" {
-		b.WriteString(fmt.Sprintf("\n  %s\n", subtitleStyle.Render("Description:")))
-		desc := ep.Description
-		// Limit description length for display
-		if len(desc) > 500 {
-			desc = desc[:497] + "..."
-		}
-		// Simple word wrap at ~70 chars
-		words := strings.Fields(desc)
-		line := "  "
-		for _, word := range words {
-			if len(line)+len(word)+1 > 72 {
-				b.WriteString(line + "\n")
-				line = "  " + word
-			} else {
-				if line == "  " {
-					line += word
-				} else {
-					line += " " + word
-				}
-			}
+		b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Audio URL:"), ep.AudioURL))
+		if media := podcast.MediaURL(ep.AudioURL); media != ep.AudioURL {
+			b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Hosted at:"), media))
 		}
-		if line != "  " {
-			b.WriteString(line + "\n")
+	}
+	if ep.Blocked {
+		b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Blocked:"), m.theme.error.Render("yes (itunes:block)")))
+	}
+	if m.previewText != "" {
+		b.WriteString(fmt.Sprintf("\n  %s\n", m.theme.subtitle.Render("Description:")))
+	}
+
+	return b.String()
+}
+
+// previewDescriptionLines wraps the previewed episode's description to the
+// window width
+func (m model) previewDescriptionLines() []string {
+	if m.cursor >= len(m.episodes) {
+		return nil
+	}
+	return wrapText(m.previewText, max(m.windowWidth-4, 20))
+}
+
+// previewVisibleLines is how many description lines fit below the header
+func (m model) previewVisibleLines() int {
+	header := strings.Count(m.previewEpisodeHeader(), "\n")
+	return max(m.windowHeight-header-5, 3)
+}
+
+// truncate shortens s to width columns, marking the cut with "...". It
+// cuts between runes and counts wide (e.g. CJK) characters as two columns.
+func truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	keep, used := 0, 0
+	for keep < len(runes) {
+		w := lipgloss.Width(string(runes[keep]))
+		if used+w > width-3 {
+			break
 		}
+		used += w
+		keep++
 	}
+	return string(runes[:keep]) + "..."
+}
 
-	b.WriteString(helpStyle.Render("\n\n  esc/b/v back • q quit"))
+// padRight pads s with spaces to width columns, which fmt's %-*s gets
+// wrong for wide characters
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-lipgloss.Width(s), 0))
+}
 
-	return b.String()
+// wrapText word-wraps text to width columns, keeping its line breaks
+func wrapText(text string, width int) []string {
+	if text == "" {
+		return nil
+	}
+
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			switch {
+			case line == "":
+				line = word
+			case lipgloss.Width(line)+1+lipgloss.Width(word) > width:
+				lines = append(lines, line)
+				line = word
+			default:
+				line += " " + word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
 }
 
-func (m model) viewDownloading() string {
+func (m model) viewConfirm() string {
 	var b strings.Builder
 
 	b.WriteString("\n")
-	b.WriteString(titleStyle.Render("Downloading..."))
+	b.WriteString(m.theme.title.Render("Ready to Download"))
 	b.WriteString("\n\n")
 
-	// Get current episode name
-	currentFile := ""
-	selected := m.getSelectedEpisodes()
-	if m.downloadIndex < len(selected) {
-		ep := selected[m.downloadIndex]
-		currentFile = fmt.Sprintf("%03d - %s.mp3", ep.Index, sanitizeFilename(ep.Title))
+	est := m.estimate
+	episodes := fmt.Sprint(len(m.getSelectedEpisodes()))
+	if est.present > 0 {
+		episodes += fmt.Sprintf(" (%d already downloaded)", est.present)
+	}
+	b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Episodes:"), episodes))
+	b.WriteString(fmt.Sprintf("  %s %s/\n", m.theme.subtitle.Render("Destination:"), m.output.dir))
+	if caps := rateSummary(m.opts.download); caps != "" {
+		b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Rate limit:"), caps))
 	}
 
-	b.WriteString(fmt.Sprintf("  Episode %d of %d\n", m.downloadIndex+1, m.downloadTotal))
-	b.WriteString(fmt.Sprintf("  %s\n\n", currentFile))
-	b.WriteString("  " + m.progress.View() + "\n")
+	var size string
+	switch {
+	case est.unknown > 0 && est.needed == 0:
+		size = m.theme.dim.Render("size unknown")
+	case est.unknown > 0:
+		size = formatSize(est.needed) + fmt.Sprintf(" (+%d of unknown size)", est.unknown)
+	default:
+		size = formatSize(est.needed)
+	}
+	b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Estimated size:"), size))
+
+	if est.free >= 0 {
+		b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Free space:"), formatSize(est.free)))
+	} else {
+		b.WriteString(fmt.Sprintf("  %s %s\n", m.theme.subtitle.Render("Free space:"), m.theme.dim.Render("unknown")))
+	}
 
-	if len(m.downloaded) > 0 {
-		b.WriteString(dimStyle.Render(fmt.Sprintf("\n  ✓ %d completed", len(m.downloaded))))
+	if est.insufficient() {
+		b.WriteString("\n  " + m.theme.error.Render("Warning: not enough free space for this batch") + "\n")
 	}
 
-	b.WriteString(helpStyle.Render("\n\n  esc/b back • q quit"))
+	b.WriteString(m.theme.help.Render("\n\n  enter/y start download • esc/b/n back • ? help • q quit"))
 
 	return b.String()
 }
 
-func (m model) viewDone() string {
+func (m model) viewDownloading() string {
 	var b strings.Builder
 
 	b.WriteString("\n")
-	b.WriteString(successStyle.Render("✓ Download Complete!"))
+	if m.halted {
+		b.WriteString(m.theme.title.Render("Paused"))
+	} else {
+		b.WriteString(m.theme.title.Render("Downloading..."))
+	}
 	b.WriteString("\n\n")
 
-	b.WriteString(fmt.Sprintf("  Downloaded %d episode(s) to:\n", len(m.downloaded)))
-	b.WriteString(fmt.Sprintf("  %s/\n\n", m.outputDir))
+	// Get current episode name
+	currentFile := ""
+	selected := m.getSelectedEpisodes()
+	if m.downloadIndex < len(selected) {
+		ep := selected[m.downloadIndex]
+		currentFile = filepath.Base(m.output.episodePath(ep))
+	}
+
+	// Long names would wrap and push the progress bar off its line
+	nameWidth := max(m.windowWidth-4, 10)
+	b.WriteString(fmt.Sprintf("  Episode %d of %d\n", m.downloadIndex+1, m.downloadTotal))
+	if m.halted {
+		b.WriteString(fmt.Sprintf("  %s\n\n", m.theme.dim.Render(truncate("next: "+currentFile, nameWidth))))
+	} else {
+		b.WriteString(fmt.Sprintf("  %s\n\n", truncate(currentFile, nameWidth)))
+		b.WriteString("  " + m.progress.View() + m.theme.dim.Render(m.eta()) + "\n")
+	}
 
-	for _, f := range m.downloaded {
-		b.WriteString(dimStyle.Render(fmt.Sprintf("  • %s\n", filepath.Base(f))))
+	if len(m.results) > 0 {
+		b.WriteString(m.theme.dim.Render(fmt.Sprintf("\n  ✓ %d completed", len(m.results))))
+	}
+	switch {
+	case m.halted:
+		b.WriteString(m.theme.success.Render("\n\n  ⏸ Paused, press p to resume"))
+	case m.paused:
+		b.WriteString(m.theme.success.Render("\n\n  ⏸ Pausing after this episode, press p to carry on"))
 	}
 
-	b.WriteString(helpStyle.Render("\n  Press enter or q to exit"))
+	b.WriteString(m.theme.help.Render("\n\n  p pause/resume • esc/b back • ? help • q quit"))
 
 	return b.String()
 }
 
-func (m model) viewError() string {
-	return fmt.Sprintf("\n%s\n\n  %s\n\n%s",
-		errorStyle.Render("Error"),
-		m.errorMsg,
-		helpStyle.Render("  Press q to exit"),
-	)
+// etaWidth is the room kept after the progress bar for the time left
+const etaWidth = len("  ETA 00:00:00")
+
+// progressWidth fits the progress bar, its percentage and the time left
+// on one line of a window width columns wide
+func progressWidth(width int) int {
+	return max(width-4-etaWidth, 10)
 }
 
-// Fetch podcast info from Apple's API
-func loadPodcast(podcastID string) tea.Cmd {
-	return func() tea.Msg {
-		// Remove "id" prefix if present
-		podcastID = strings.TrimPrefix(strings.ToLower(podcastID), "id")
+// eta estimates the time left on the running episode from its progress
+// so far, once there is enough to go on
+func (m model) eta() string {
+	elapsed := time.Since(m.episodeStart)
+	done := m.percent - m.startPercent
+	if m.episodeStart.IsZero() || elapsed < 2*time.Second || done < 0.01 {
+		return ""
+	}
+	left := time.Duration(float64(elapsed) * (1 - m.percent) / done)
+	return "  ETA " + podcast.FormatDuration(left)
+}
 
-		// Fetch from iTunes API
-		url := fmt.Sprintf("https://itunes.apple.com/lookup?id=%s&entity=podcast", podcastID)
-		resp, err := http.Get(url)
-		if err != nil {
-			return errorMsg{err: fmt.Errorf("failed to lookup podcast: %w", err)}
-		}
-		defer resp.Body.Close()
+func (m model) viewDone() string {
+	var b strings.Builder
 
-		var result iTunesResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return errorMsg{err: fmt.Errorf("failed to parse response: %w", err)}
-		}
+	// Results from before a retry come first
+	results := append(slices.Clone(m.earlier), m.results...)
+	var counts [statusFailed + 1]int
+	for _, r := range results {
+		counts[r.status]++
+	}
 
-		if result.ResultCount == 0 {
-			return errorMsg{err: fmt.Errorf("no podcast found with ID: %s", podcastID)}
-		}
+	b.WriteString("\n")
+	switch {
+	case m.stopErr != nil:
+		b.WriteString(m.theme.error.Render("✗ Download stopped"))
+	case counts[statusFailed] > 0:
+		b.WriteString(m.theme.error.Render("✗ Download finished with errors"))
+	default:
+		b.WriteString(m.theme.success.Render("✓ Download Complete!"))
+	}
+	b.WriteString("\n\n")
 
-		r := result.Results[0]
-		info := PodcastInfo{
-			Name:       r.CollectionName,
-			Artist:     r.ArtistName,
-			FeedURL:    r.FeedURL,
-			ArtworkURL: r.ArtworkURL600,
+	b.WriteString(fmt.Sprintf("  %d downloaded • %d already present • %d failed, in:\n",
+		counts[statusDownloaded], counts[statusExisted], counts[statusFailed]))
+	b.WriteString(fmt.Sprintf("  %s/\n\n", m.output.dir))
+
+	for _, r := range results {
+		name := filepath.Base(r.filename)
+		switch r.status {
+		case statusDownloaded:
+			b.WriteString(m.theme.success.Render("  ✓ "+name) + "\n")
+			if r.warning != "" {
+				b.WriteString(m.theme.dim.Render("    ! "+r.warning) + "\n")
+			}
+		case statusExisted:
+			b.WriteString(m.theme.dim.Render("  = "+name+" (already present)") + "\n")
+		case statusFailed:
+			b.WriteString(m.theme.error.Render(fmt.Sprintf("  ✗ %s: %v", name, r.err)) + "\n")
 		}
+	}
 
-		if info.ArtworkURL == "" {
-			info.ArtworkURL = r.ArtworkURL100
+	if m.stopErr != nil {
+		selected := m.getSelectedEpisodes()
+		notStarted := selected[min(len(m.results), len(selected)):]
+		b.WriteString("\n  Stopped: the output folder can't be written to")
+		if len(notStarted) > 0 {
+			b.WriteString(fmt.Sprintf(". Not attempted (%d):", len(notStarted)))
 		}
-
-		if info.FeedURL == "" {
-			return errorMsg{err: fmt.Errorf("no RSS feed URL found for this podcast")}
+		b.WriteString("\n")
+		for _, ep := range notStarted {
+			b.WriteString(m.theme.dim.Render(fmt.Sprintf("  • %s\n", filepath.Base(m.output.episodePath(ep)))))
 		}
+	}
 
-		// Parse RSS feed
-		fp := gofeed.NewParser()
-		feed, err := fp.ParseURL(info.FeedURL)
-		if err != nil {
-			return errorMsg{err: fmt.Errorf("failed to parse RSS feed: %w", err)}
+	if m.artworkErr != nil {
+		b.WriteString(m.theme.dim.Render(fmt.Sprintf("\n  ! %v\n", m.artworkErr)))
+	}
+	if m.feedErr != nil {
+		b.WriteString(m.theme.dim.Render(fmt.Sprintf("\n  ! feed not saved: %v\n", m.feedErr)))
+	}
+
+	if len(m.skipped) > 0 {
+		b.WriteString(fmt.Sprintf("\n  Skipped %d episode(s), max total size of %s reached:\n", len(m.skipped), formatSize(m.budget.limit)))
+		for _, f := range m.skipped {
+			b.WriteString(m.theme.dim.Render(fmt.Sprintf("  • %s\n", f)))
 		}
+	}
 
-		var episodes []Episode
-		for i, item := range feed.Items {
-			audioURL := ""
+	if m.notice != "" {
+		b.WriteString("\n  " + m.theme.success.Render(m.notice) + "\n")
+	}
+	if retry := len(m.unfinished()); retry > 0 {
+		b.WriteString(m.theme.help.Render(fmt.Sprintf("\n  r retry %d unfinished • o open folder • enter/q exit • ? help", retry)))
+	} else {
+		b.WriteString(m.theme.help.Render("\n  o open folder • enter or q to exit"))
+	}
 
-			// Find audio enclosure
-			for _, enc := range item.Enclosures {
-				if strings.Contains(enc.Type, "audio") || strings.HasSuffix(enc.URL, ".mp3") {
-					audioURL = enc.URL
-					break
-				}
-			}
+	return b.String()
+}
 
-			if audioURL == "" {
-				continue
-			}
+func (m model) viewError() string {
+	var b strings.Builder
 
-			var pubDate time.Time
-			if item.PublishedParsed != nil {
-				pubDate = *item.PublishedParsed
-			}
+	b.WriteString("\n" + m.theme.error.Render("Error") + "\n\n")
+	b.WriteString("  " + m.errorMsg + "\n")
+	if m.errorHint != "" {
+		b.WriteString("\n  " + m.theme.dim.Render(m.errorHint) + "\n")
+	}
+	b.WriteString("\n" + m.theme.help.Render("  Press / to search again or q to exit"))
 
-			duration := ""
-			if item.ITunesExt != nil {
-				duration = item.ITunesExt.Duration
-			}
+	return b.String()
+}
 
-			episodes = append(episodes, Episode{
-				Index:       i + 1,
-				Title:       item.Title,
-				Description: item.Description,
-				AudioURL:    audioURL,
-				PubDate:     pubDate,
-				Duration:    duration,
-			})
-		}
+// errorHint suggests what to do about an error from the podcast package
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, podcast.ErrNetwork):
+		return "Check your internet connection (or proxy) and try again."
+	case errors.Is(err, podcast.ErrNotFound):
+		return "Check the spelling or ID, or try --index podcastindex for shows Apple doesn't list."
+	case errors.Is(err, podcast.ErrNoFeed):
+		return "This show doesn't publish a public RSS feed, so it can't be downloaded."
+	case errors.Is(err, podcast.ErrNoEpisodes):
+		return "The feed has no episodes with audio attached; it may be video-only or empty."
+	}
+	return ""
+}
 
-		if len(episodes) == 0 {
-			return errorMsg{err: fmt.Errorf("no downloadable episodes found")}
-		}
+// allHiddenError is a feed whose episodes were all hidden by the options
+type allHiddenError string
 
-		return podcastLoadedMsg{info: info, episodes: episodes}
+func (e allHiddenError) Error() string { return string(e) }
+
+// hiddenAll explains an episode list that hiding future-dated episodes and
+// those older than within has emptied, or returns nil when some are left
+func hiddenAll(left, future, older int, within *podcast.Window) error {
+	switch {
+	case left > 0:
+		return nil
+	case older == 0 && future > 0:
+		return allHiddenError(fmt.Sprintf("all %d episodes are future-dated; use --include-future to list them", future))
+	case older > 0 && future == 0:
+		return allHiddenError(fmt.Sprintf("no episodes within %s; all %d are older", within, older))
+	case older > 0:
+		return allHiddenError(fmt.Sprintf("no episodes within %s: %d are older and %d future-dated", within, older, future))
 	}
+	return nil
 }
 
-func downloadFileWithProgress(filepath string, url string) error {
-	// Check if already exists
-	if _, err := os.Stat(filepath); err == nil {
-		return nil
+// Fetch podcast info from Apple's API
+func loadPodcast(podcastID string) tea.Cmd {
+	return func() tea.Msg {
+		return podcastLoaded(podcast.LoadByID(podcastID))
 	}
+}
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
+// spaceEstimate is the result of the disk-space preflight for a batch
+type spaceEstimate struct {
+	needed  int64 // bytes for episodes whose size is known
+	unknown int   // episodes whose size couldn't be determined
+	present int   // episodes already on disk, not counted in needed
+	free    int64 // bytes available on the target filesystem; -1 if unknown
+}
+
+// insufficient reports whether the known size alone exceeds the free space
+func (e spaceEstimate) insufficient() bool {
+	return e.free >= 0 && e.needed > e.free
+}
+
+// estimateSpace sizes the episodes not yet present in output with HEAD
+// requests and checks them against the free space on its filesystem
+func estimateSpace(output layout, episodes []podcast.Episode, jitter time.Duration) spaceEstimate {
+	est := spaceEstimate{free: -1}
+
+	// Most feeds give the size as the enclosure length; only the rest need
+	// a request, a few HEAD requests at a time to keep large batches quick
+	var sizes []int64
+	var unsized []podcast.Episode
+	for _, ep := range episodes {
+		switch _, err := os.Stat(output.episodePath(ep)); {
+		case err == nil:
+			est.present++
+		case ep.ExpectedSize > 0:
+			sizes = append(sizes, ep.ExpectedSize)
+		default:
+			unsized = append(unsized, ep)
+		}
 	}
-	defer resp.Body.Close()
 
-	out, err := os.Create(filepath)
-	if err != nil {
-		return err
+	headSizes := make([]int64, len(unsized))
+	sem := make(chan struct{}, 8)
+	var wg sync.WaitGroup
+	for i, ep := range unsized {
+		if i > 0 && i < cap(sem) {
+			stagger(jitter)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			headSizes[i] = podcast.HeadContentLength(ep.AudioURL)
+		}()
 	}
-	defer out.Close()
+	wg.Wait()
+	sizes = append(sizes, headSizes...)
 
-	totalSize := resp.ContentLength
-	downloaded := int64(0)
-	lastPercent := float64(0)
+	for _, size := range sizes {
+		if size > 0 {
+			est.needed += size
+		} else {
+			est.unknown++
+		}
+	}
 
-	buf := make([]byte, 32*1024)
+	// The podcast folder may not exist yet; check its nearest existing parent
+	dir := output.dir
 	for {
-		n, err := resp.Body.Read(buf)
-		if n > 0 {
-			out.Write(buf[:n])
-			downloaded += int64(n)
-			if totalSize > 0 {
-				percent := float64(downloaded) / float64(totalSize)
-				// Only send updates every 1% to avoid flooding
-				if percent-lastPercent >= 0.01 || percent >= 1.0 {
-					lastPercent = percent
-					if program != nil {
-						program.Send(downloadProgressMsg(percent))
-					}
-				}
-			}
-		}
-		if err == io.EOF {
+		if _, err := os.Stat(dir); err == nil {
 			break
 		}
-		if err != nil {
-			return err
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
 		}
+		dir = parent
+	}
+	if free, err := freeDiskSpace(dir); err == nil {
+		est.free = free
 	}
 
-	return nil
+	return est
 }
 
-func addID3Tags(filepath string, ep Episode, info PodcastInfo) error {
-	tag, err := id3v2.Open(filepath, id3v2.Options{Parse: true})
-	if err != nil {
-		// Create new tag if file doesn't have one
-		tag = id3v2.NewEmptyTag()
+// admits reports whether downloading ep to filePath stays within the size
+// cap, estimating its size from the feed or with a HEAD request. Existing
+// files are always admitted since nothing is written for them. Once a
+// download is refused, all later ones are too.
+func (b *sizeBudget) admits(filePath string, ep podcast.Episode) bool {
+	if b.limit <= 0 {
+		return true
 	}
-	defer tag.Close()
-
-	tag.SetTitle(ep.Title)
-	tag.SetArtist(info.Artist)
-	tag.SetAlbum(info.Name)
-
-	// Set track number
-	trackFrame := id3v2.TextFrame{
-		Encoding: id3v2.EncodingUTF8,
-		Text:     strconv.Itoa(ep.Index),
+	if _, err := os.Stat(filePath); err == nil {
+		return true
 	}
-	tag.AddFrame(tag.CommonID("Track number/Position in set"), trackFrame)
-
-	return tag.Save()
+	if b.exhausted {
+		return false
+	}
+	estimate := podcast.EpisodeSize(ep)
+	if b.written >= b.limit || (estimate > 0 && b.written+estimate > b.limit) {
+		b.exhausted = true
+		return false
+	}
+	return true
 }
 
-func sanitizeFilename(name string) string {
-	// Remove invalid characters
-	re := regexp.MustCompile(`[<>:"/\\|?*]`)
-	name = re.ReplaceAllString(name, "")
-	name = strings.TrimSpace(name)
+// parseSize parses a human-readable size such as 500M, 2G or 1.5GiB
+// (binary units); a bare number is bytes
+func parseSize(s string) (int64, error) {
+	num := strings.ToUpper(strings.TrimSpace(s))
+	num = strings.TrimSuffix(strings.TrimSuffix(num, "B"), "I")
+
+	multiplier := float64(1)
+	if n := len(num); n > 0 {
+		switch num[n-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			num = num[:n-1]
+		}
+	}
 
-	// Limit length
-	if len(name) > 100 {
-		name = name[:100]
+	value, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 500M or 2G)", s)
 	}
+	return int64(value * multiplier), nil
+}
 
-	if name == "" {
-		return "episode"
+// formatSize renders a byte count in binary units, e.g. 1.5 GB
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
 	}
-	return name
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
 }
 
 // searchPodcasts searches for podcasts using Apple's Search API
 func searchPodcasts(query string) tea.Cmd {
 	return func() tea.Msg {
-		// URL encode the query
-		encodedQuery := strings.ReplaceAll(query, " ", "+")
-		url := fmt.Sprintf("https://itunes.apple.com/search?term=%s&media=podcast&limit=25", encodedQuery)
-
-		resp, err := http.Get(url)
+		results, err := podcast.SearchApple(query)
 		if err != nil {
 			return errorMsg{err: fmt.Errorf("failed to search podcasts: %w", err)}
 		}
-		defer resp.Body.Close()
-
-		var result iTunesResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return errorMsg{err: fmt.Errorf("failed to parse search results: %w", err)}
-		}
-
-		var results []SearchResult
-		for _, r := range result.Results {
-			if r.FeedURL == "" {
-				continue // Skip podcasts without RSS feed
-			}
-
-			results = append(results, SearchResult{
-				ID:         strconv.Itoa(r.CollectionID),
-				Name:       r.CollectionName,
-				Artist:     r.ArtistName,
-				FeedURL:    r.FeedURL,
-				ArtworkURL: r.ArtworkURL600,
-				Source:     ProviderApple,
-			})
-		}
-
 		return searchResultsMsg{results: results}
 	}
 }
@@ -1084,342 +2073,1078 @@ func searchPodcasts(query string) tea.Cmd {
 // searchPodcastIndex searches using Podcast Index API
 func searchPodcastIndex(query string) tea.Cmd {
 	return func() tea.Msg {
-		apiKey := strings.TrimSpace(os.Getenv("PODCASTINDEX_API_KEY"))
-		apiSecret := strings.TrimSpace(os.Getenv("PODCASTINDEX_API_SECRET"))
-
-		if apiKey == "" || apiSecret == "" {
+		if !podcast.HasPodcastIndexCredentials() {
 			return errorMsg{err: fmt.Errorf("Podcast Index API credentials not set.\nSet PODCASTINDEX_API_KEY and PODCASTINDEX_API_SECRET environment variables.\nGet free API keys at: https://api.podcastindex.org")}
 		}
 
-		// Build authentication headers (hash = sha1(apiKey + apiSecret + unixTime))
-		apiHeaderTime := strconv.FormatInt(time.Now().Unix(), 10)
-		hashInput := apiKey + apiSecret + apiHeaderTime
-		h := sha1.New()
-		h.Write([]byte(hashInput))
-		authHash := hex.EncodeToString(h.Sum(nil))
-
-		// URL encode the query
-		encodedQuery := url.QueryEscape(query)
-		apiURL := fmt.Sprintf("https://api.podcastindex.org/api/1.0/search/byterm?q=%s&max=25", encodedQuery)
-
-		req, err := http.NewRequest("GET", apiURL, nil)
-		if err != nil {
-			return errorMsg{err: fmt.Errorf("failed to create request: %w", err)}
-		}
-
-		// Set required headers
-		req.Header.Set("User-Agent", "PodcastDownload/1.0")
-		req.Header.Set("X-Auth-Key", apiKey)
-		req.Header.Set("X-Auth-Date", apiHeaderTime)
-		req.Header.Set("Authorization", authHash)
-
-		client := &http.Client{Timeout: 30 * time.Second}
-		resp, err := client.Do(req)
+		results, err := podcast.SearchPodcastIndex(query)
 		if err != nil {
 			return errorMsg{err: fmt.Errorf("failed to search Podcast Index: %w", err)}
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			return errorMsg{err: fmt.Errorf("Podcast Index API error (%d): %s", resp.StatusCode, string(body))}
-		}
-
-		var result podcastIndexResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return errorMsg{err: fmt.Errorf("failed to parse search results: %w", err)}
-		}
-
-		var results []SearchResult
-		for _, feed := range result.Feeds {
-			if feed.URL == "" {
-				continue
-			}
-
-			results = append(results, SearchResult{
-				ID:         strconv.Itoa(feed.ID),
-				Name:       feed.Title,
-				Artist:     feed.Author,
-				FeedURL:    feed.URL,
-				ArtworkURL: feed.Image,
-				Source:     ProviderPodcastIndex,
-			})
-		}
-
 		return searchResultsMsg{results: results}
 	}
 }
 
-// hasPodcastIndexCredentials checks if Podcast Index API credentials are set
-func hasPodcastIndexCredentials() bool {
-	apiKey := strings.TrimSpace(os.Getenv("PODCASTINDEX_API_KEY"))
-	apiSecret := strings.TrimSpace(os.Getenv("PODCASTINDEX_API_SECRET"))
-	return apiKey != "" && apiSecret != ""
-}
-
-// searchAppleResults performs Apple search and returns results directly (for use in combined search)
-func searchAppleResults(query string) ([]SearchResult, error) {
-	encodedQuery := strings.ReplaceAll(query, " ", "+")
-	url := fmt.Sprintf("https://itunes.apple.com/search?term=%s&media=podcast&limit=25", encodedQuery)
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
+// expandPath expands a leading ~ and environment variables in a path, for
+// arguments the shell left alone (e.g. quoted ones)
+func expandPath(p string) (string, error) {
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, "~"+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		p = filepath.Join(home, p[1:])
 	}
-	defer resp.Body.Close()
+	return os.ExpandEnv(p), nil
+}
 
-	var result iTunesResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
+// parseCountry validates a two-letter store country code
+func parseCountry(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+		return "", fmt.Errorf("invalid country code %q (expected two letters, e.g. FR)", code)
 	}
+	return code, nil
+}
 
-	var results []SearchResult
-	for _, r := range result.Results {
-		if r.FeedURL == "" {
+// localeCountry returns the country of the system locale (e.g. FR for
+// fr_FR.UTF-8), or "" when the locale doesn't name one
+func localeCountry() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		locale := os.Getenv(name)
+		if locale == "" {
 			continue
 		}
-		results = append(results, SearchResult{
-			ID:         strconv.Itoa(r.CollectionID),
-			Name:       r.CollectionName,
-			Artist:     r.ArtistName,
-			FeedURL:    r.FeedURL,
-			ArtworkURL: r.ArtworkURL600,
-			Source:     ProviderApple,
-		})
+		locale, _, _ = strings.Cut(locale, ".")
+		locale, _, _ = strings.Cut(locale, "@")
+		_, country, ok := strings.Cut(locale, "_")
+		if !ok {
+			return ""
+		}
+		code, err := parseCountry(country)
+		if err != nil {
+			return ""
+		}
+		return code
 	}
-	return results, nil
+	return ""
 }
 
-// searchPodcastIndexResults performs Podcast Index search and returns results directly (for use in combined search)
-func searchPodcastIndexResults(query string) ([]SearchResult, error) {
-	apiKey := strings.TrimSpace(os.Getenv("PODCASTINDEX_API_KEY"))
-	apiSecret := strings.TrimSpace(os.Getenv("PODCASTINDEX_API_SECRET"))
-
-	apiHeaderTime := strconv.FormatInt(time.Now().Unix(), 10)
-	hashInput := apiKey + apiSecret + apiHeaderTime
-	h := sha1.New()
-	h.Write([]byte(hashInput))
-	authHash := hex.EncodeToString(h.Sum(nil))
-
-	encodedQuery := url.QueryEscape(query)
-	apiURL := fmt.Sprintf("https://api.podcastindex.org/api/1.0/search/byterm?q=%s&max=25", encodedQuery)
-
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return nil, err
+// parseBaseURL validates an API base URL and strips any trailing slash
+func parseBaseURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid base URL %q: must be an absolute http(s) URL", raw)
 	}
+	return strings.TrimRight(u.String(), "/"), nil
+}
 
-	req.Header.Set("User-Agent", "PodcastDownload/1.0")
-	req.Header.Set("X-Auth-Key", apiKey)
-	req.Header.Set("X-Auth-Date", apiHeaderTime)
-	req.Header.Set("Authorization", authHash)
+// searchBoth searches both Apple and Podcast Index APIs concurrently and combines results
+func searchBoth(query string) tea.Cmd {
+	return func() tea.Msg {
+		results, err := podcast.SearchBoth(query)
+		if err != nil {
+			return errorMsg{err: err}
+		}
+		return searchResultsMsg{results: results}
+	}
+}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+// loadPodcastFromFeed loads a podcast directly from its RSS feed URL
+func loadPodcastFromFeed(feedURL, name, artist, artworkURL string) tea.Cmd {
+	return func() tea.Msg {
+		return podcastLoaded(podcast.LoadFeed(feedURL, name, artist, artworkURL))
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, string(body))
+// followNewFeedURL loads the feed at the URL info's feed announced, noting
+// the old one as where it moved from
+func followNewFeedURL(info podcast.PodcastInfo) tea.Cmd {
+	return func() tea.Msg {
+		moved, episodes, err := podcast.LoadFeed(info.NewFeedURL, info.Name, info.Artist, info.ArtworkURL)
+		if err != nil {
+			return errorMsg{err: err}
+		}
+		moved.MovedFrom = info.FeedURL
+		return podcastLoadedMsg{info: moved, episodes: episodes}
 	}
+}
 
-	var result podcastIndexResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
+// mergeListings adds the episodes of the show's other directory listings
+func mergeListings(info podcast.PodcastInfo, episodes []podcast.Episode) tea.Cmd {
+	return func() tea.Msg {
+		info, episodes := podcast.MergeListings(info, episodes)
+		return podcastLoadedMsg{info: info, episodes: episodes, merged: true}
 	}
+}
 
-	var results []SearchResult
-	for _, feed := range result.Feeds {
-		if feed.URL == "" {
-			continue
-		}
-		results = append(results, SearchResult{
-			ID:         strconv.Itoa(feed.ID),
-			Name:       feed.Title,
-			Artist:     feed.Author,
-			FeedURL:    feed.URL,
-			ArtworkURL: feed.Image,
-			Source:     ProviderPodcastIndex,
-		})
+// blockWarning describes any itunes:block set by the publisher, which asks
+// directories not to list the feed or episodes. Downloads still proceed.
+func blockWarning(info podcast.PodcastInfo, episodes []podcast.Episode) string {
+	if info.Blocked {
+		return "The publisher marked this feed itunes:block (not meant for redistribution)"
+	}
+	if n := podcast.BlockedEpisodes(episodes); n > 0 {
+		return fmt.Sprintf("The publisher marked %d episode(s) itunes:block (not meant for redistribution)", n)
 	}
-	return results, nil
+	return ""
 }
 
-// searchBoth searches both Apple and Podcast Index APIs concurrently and combines results
-func searchBoth(query string) tea.Cmd {
-	return func() tea.Msg {
-		var wg sync.WaitGroup
-		var appleResults, piResults []SearchResult
-		var appleErr, piErr error
+// podcastLoaded converts a load result into the matching message
+func podcastLoaded(info podcast.PodcastInfo, episodes []podcast.Episode, err error) tea.Msg {
+	if err != nil {
+		return errorMsg{err: err}
+	}
+	return podcastLoadedMsg{info: info, episodes: episodes}
+}
 
-		wg.Add(2)
+// fundingLine shows a funding link with its label, when the feed gives one
+func fundingLine(f podcast.Funding) string {
+	if f.Text == "" {
+		return f.URL
+	}
+	return f.Text + " (" + f.URL + ")"
+}
 
-		// Search Apple
-		go func() {
-			defer wg.Done()
-			appleResults, appleErr = searchAppleResults(query)
-		}()
+// fetchFeedPreview parses a search result's feed so the preview can show
+// its size and newest episodes; the parsed feed is cached for a later selection
+func fetchFeedPreview(feedURL string) tea.Cmd {
+	return func() tea.Msg {
+		doc, err := podcast.FetchFeed(feedURL)
+		if err != nil {
+			return feedPreviewMsg{feedURL: feedURL, preview: feedPreview{err: err}}
+		}
+		feed, err := doc.Parse()
+		if err != nil {
+			return feedPreviewMsg{feedURL: feedURL, preview: feedPreview{err: err}}
+		}
+
+		episodes := podcast.ParseEpisodes(feed)
+		preview := feedPreview{
+			feed:         feed,
+			data:         doc.Data,
+			movedTo:      doc.MovedTo,
+			newFeedURL:   podcast.AnnouncedFeedURL(feed, feedURL),
+			blocked:      podcast.FeedBlocked(feed),
+			language:     strings.TrimSpace(feed.Language),
+			funding:      podcast.ParseFunding(feed),
+			value:        podcast.ParseValue(feed),
+			episodeCount: len(episodes),
+		}
+		podcast.SortEpisodes(episodes, podcast.SortDate, false)
+		preview.recent = episodes[:min(len(episodes), previewRecentCount)]
+		return feedPreviewMsg{feedURL: feedURL, preview: preview}
+	}
+}
 
-		// Search Podcast Index
-		go func() {
-			defer wg.Done()
-			piResults, piErr = searchPodcastIndexResults(query)
-		}()
+// downloadEpisodes downloads and tags episodes into the podcast's folder
+// without the TUI, printing one line per episode
+func downloadEpisodes(info podcast.PodcastInfo, episodes []podcast.Episode, baseDir string, opts options, budget *sizeBudget) error {
+	opts = opts.forPodcast(info)
+	output := newLayout(info, baseDir, len(episodes), opts)
+	if err := os.MkdirAll(output.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output folder: %w", err)
+	}
 
-		wg.Wait()
+	// As in the TUI, a feed copy that can't be saved doesn't hold up the
+	// downloads
+	if opts.saveFeed {
+		if err := podcast.SaveFeed(info, output.feedPath()); err != nil {
+			fmt.Printf("  ! feed not saved: %v\n", err)
+		}
+	}
 
-		// If both failed, return error
-		if appleErr != nil && piErr != nil {
-			return errorMsg{err: fmt.Errorf("search failed: Apple: %v, Podcast Index: %v", appleErr, piErr)}
+	tags, err := withArtwork(opts.tags, info, output.coverPath(), opts)
+	if err != nil {
+		fmt.Printf("  ! %v\n", err)
+	}
+	opts.tags = tags
+
+	// A partial file from an interrupted run must be completed, not skipped
+	if pending, _ := findResume(info, baseDir, episodes, opts); len(pending) > 0 {
+		fmt.Printf("  resuming an interrupted download, %d episode(s) pending\n", len(pending))
+		if opts.download.OnExists == "" || opts.download.OnExists == podcast.ExistsSkip {
+			opts.download.OnExists = podcast.ExistsCheck
 		}
+	}
 
-		// Combine results - Apple first, then Podcast Index (deduplicated by feed URL)
-		var combined []SearchResult
-		seenFeedURLs := make(map[string]bool)
+	est := estimateSpace(output, episodes, opts.startJitter)
+	if budget.limit > 0 && est.needed > budget.limit-budget.written {
+		// Only what fits under --max-total-size will be written
+		est.needed = max(budget.limit-budget.written, 0)
+	}
+	if est.insufficient() {
+		return fmt.Errorf("not enough disk space: need about %s, %s available", formatSize(est.needed), formatSize(est.free))
+	}
 
-		if appleErr == nil {
-			for _, r := range appleResults {
-				normalizedURL := strings.ToLower(strings.TrimSuffix(r.FeedURL, "/"))
-				if !seenFeedURLs[normalizedURL] {
-					seenFeedURLs[normalizedURL] = true
-					combined = append(combined, r)
-				}
-			}
+	var skipped []string
+	var unfinished []podcast.Episode // failed or skipped so far
+	var failures []podcast.Episode
+	for i, ep := range episodes {
+		filePath := output.episodePath(ep)
+		if !budget.admits(filePath, ep) {
+			skipped = append(skipped, filepath.Base(filePath))
+			unfinished = append(unfinished, ep)
+			continue
 		}
-		if piErr == nil {
-			for _, r := range piResults {
-				normalizedURL := strings.ToLower(strings.TrimSuffix(r.FeedURL, "/"))
-				if !seenFeedURLs[normalizedURL] {
-					seenFeedURLs[normalizedURL] = true
-					combined = append(combined, r)
+		saveResume(output.resumePath(), info, append(slices.Clone(unfinished), episodes[i:]...))
+		fmt.Printf("  [%d/%d] %s\n", i+1, len(episodes), filepath.Base(filePath))
+		if opts.started != nil {
+			opts.started(i, filePath)
+		}
+		_, statErr := os.Stat(filePath)
+		existed := statErr == nil
+		dl := opts.download
+		if opts.checksums {
+			dl.Hash = sha256.New()
+		}
+		size, err := podcast.Download(filePath, ep.AudioURL, dl)
+		budget.written += size
+		if err != nil {
+			fmt.Printf("    ✗ %v\n", err)
+			failures = append(failures, ep)
+			unfinished = append(unfinished, ep)
+			continue
+		}
+		if existed && size > 0 {
+			fmt.Printf("    already present, completed or replaced\n")
+			existed = false
+		}
+		if !existed {
+			if warning := sizeWarning(ep, filePath); warning != "" {
+				fmt.Printf("    ! %s\n", warning)
+			}
+			podcast.FillDuration(&ep, filePath)
+			podcast.AddID3Tags(filePath, ep, info, opts.tags)
+			recordDownload(info, ep, filePath) // history is best-effort
+			if opts.checksums {
+				if err := recordChecksum(filePath, dl.Hash.Sum(nil)); err != nil {
+					fmt.Printf("    ! checksum not recorded: %v\n", err)
 				}
 			}
+		} else if opts.retagExisting {
+			podcast.AddID3Tags(filePath, ep, info, opts.tags)
+			refreshChecksum(filePath)
+			fmt.Printf("    already present, tags updated\n")
+		}
+		if opts.newest {
+			fmt.Printf("    → %s\n", filePath)
 		}
+	}
+
+	saveResume(output.resumePath(), info, unfinished)
 
-		return searchResultsMsg{results: combined}
+	if len(skipped) > 0 {
+		fmt.Printf("  Skipped %d episode(s), max total size of %s reached:\n", len(skipped), formatSize(budget.limit))
+		for _, name := range skipped {
+			fmt.Printf("    - %s\n", name)
+		}
+	}
+
+	if len(failures) > 0 {
+		return &episodesFailed{episodes: failures, total: len(episodes)}
 	}
+	return nil
 }
 
-// loadPodcastFromFeed loads a podcast directly from its RSS feed URL
-func loadPodcastFromFeed(feedURL, name, artist, artworkURL string) tea.Cmd {
-	return func() tea.Msg {
-		info := PodcastInfo{
-			Name:       name,
-			Artist:     artist,
-			FeedURL:    feedURL,
-			ArtworkURL: artworkURL,
-		}
+// sizeWarning checks a finished download against the size its enclosure
+// announced, before tagging grows the file, and describes a mismatch
+func sizeWarning(ep podcast.Episode, filePath string) string {
+	fi, err := os.Stat(filePath)
+	if err != nil || !ep.SizeMismatch(fi.Size()) {
+		return ""
+	}
+	return fmt.Sprintf("size %s differs from the %s the feed lists", formatSize(fi.Size()), formatSize(ep.ExpectedSize))
+}
 
-		// Parse RSS feed
-		fp := gofeed.NewParser()
-		feed, err := fp.ParseURL(feedURL)
-		if err != nil {
-			return errorMsg{err: fmt.Errorf("failed to parse RSS feed: %w", err)}
-		}
+// withArtwork adds the podcast's cover to tags when --embed-artwork is set,
+// and with --cover saves it to coverPath, unless that is "". The tags come
+// back without it if it can't be fetched or converted.
+func withArtwork(tags podcast.TagOptions, info podcast.PodcastInfo, coverPath string, opts options) (podcast.TagOptions, error) {
+	cover := opts.cover && coverPath != ""
+	if (!opts.embedArtwork && !cover) || info.ArtworkURL == "" {
+		return tags, nil
+	}
+
+	// One download serves both: the largest image for the folder, which is
+	// scaled back down to the usual size for the tags
+	url := info.ArtworkURL
+	if cover {
+		url = podcast.LargestArtworkURL(url)
+	}
+	art, err := podcast.FetchArtwork(url, opts.artworkJPEG)
+	if err != nil && url != info.ArtworkURL {
+		url = info.ArtworkURL
+		art, err = podcast.FetchArtwork(url, opts.artworkJPEG)
+	}
+	if err != nil {
+		missed := "embedded"
+		switch {
+		case cover && opts.embedArtwork:
+			missed = "embedded or saved"
+		case cover:
+			missed = "saved"
+		}
+		return tags, fmt.Errorf("artwork not %s: %w", missed, err)
+	}
 
-		// Use feed title/author if not provided
-		if info.Name == "" && feed.Title != "" {
-			info.Name = feed.Title
+	var errs []error
+	if cover {
+		if err := saveCover(coverPath, art); err != nil {
+			errs = append(errs, fmt.Errorf("%s not saved: %w", coverFile, err))
 		}
-		if info.Artist == "" && feed.Author != nil {
-			info.Artist = feed.Author.Name
+	}
+	if opts.embedArtwork {
+		if url != info.ArtworkURL {
+			art, err = art.Scaled(podcast.ArtworkWidth(info.ArtworkURL))
 		}
-		if info.ArtworkURL == "" && feed.Image != nil {
-			info.ArtworkURL = feed.Image.URL
+		if err != nil {
+			errs = append(errs, fmt.Errorf("artwork not embedded: %w", err))
+		} else {
+			tags.Artwork = &art
 		}
+	}
+	return tags, errors.Join(errs...)
+}
 
-		var episodes []Episode
-		for i, item := range feed.Items {
-			audioURL := ""
+// coverFile is the name --cover saves the podcast's cover under, the one
+// Jellyfin, Plex, Kodi and most file managers show for a folder
+const coverFile = "folder.jpg"
 
-			// Find audio enclosure
-			for _, enc := range item.Enclosures {
-				if strings.Contains(enc.Type, "audio") || strings.HasSuffix(enc.URL, ".mp3") {
-					audioURL = enc.URL
-					break
-				}
+// saveCover writes art to path as JPEG
+func saveCover(path string, art podcast.Artwork) error {
+	art, err := art.JPEG()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, art.Data, 0644)
+}
+
+// rateSummary describes the effective download rate caps, or "" if none
+func rateSummary(dl podcast.DownloadOptions) string {
+	var caps []string
+	if dl.MaxRate > 0 {
+		caps = append(caps, formatSize(dl.MaxRate)+"/s per file")
+	}
+	if dl.Limiter != nil {
+		caps = append(caps, formatSize(int64(dl.Limiter.Limit()))+"/s total")
+	}
+	return strings.Join(caps, ", ")
+}
+
+// readInputs reads one podcast input per line, skipping blanks and # comments
+func readInputs(r io.Reader) ([]string, error) {
+	var inputs []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		inputs = append(inputs, line)
+	}
+	return inputs, scanner.Err()
+}
+
+// resolvedInput is a batch input's podcast, loaded ahead of its downloads
+type resolvedInput struct {
+	info     podcast.PodcastInfo
+	episodes []podcast.Episode
+	err      error
+
+	// with --update, when the feed was last downloaded, if it hasn't
+	// changed since; info then only has its name and URL
+	unchangedSince time.Time
+}
+
+// resolveAll loads the podcasts behind inputs with up to opts.parallelFeeds
+// feeds in flight at once. The i-th channel delivers the i-th input's result, so
+// callers can work through them in order while later feeds still load.
+// With opts.mergeListings, each podcast also gets the episodes of its other
+// listings. Inputs whose feed checks says is unchanged aren't loaded.
+func resolveAll(inputs []string, provider podcast.SearchProvider, opts options, checks *feedChecks) []chan resolvedInput {
+	ready := make([]chan resolvedInput, len(inputs))
+	for i := range ready {
+		ready[i] = make(chan resolvedInput, 1)
+	}
+
+	jobs := make(chan int)
+	go func() {
+		for i := range inputs {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+	go func() {
+		for w := range max(opts.parallelFeeds, 1) {
+			if w > 0 {
+				stagger(opts.startJitter)
 			}
+			go func() {
+				for i := range jobs {
+					r, ok := checks.resolve(inputs[i])
+					if !ok {
+						r.info, r.episodes, r.err = podcast.Resolve(inputs[i], provider, opts.byAuthor)
+					}
+					if r.err == nil && r.unchangedSince.IsZero() && opts.mergeListings {
+						r.info, r.episodes = podcast.MergeListings(r.info, r.episodes)
+					}
+					ready[i] <- r
+				}
+			}()
+		}
+	}()
+	return ready
+}
+
+// stagger pauses for a random time up to jitter. Concurrent workers are
+// started with a stagger between them so they don't all hit the same host
+// at once, which sensitive CDNs answer with 429s or resets.
+func stagger(jitter time.Duration) {
+	if jitter > 0 {
+		time.Sleep(rand.N(jitter))
+	}
+}
+
+// isSearchTerm reports whether input is resolved by searching, rather than
+// naming a podcast by ID, feed URL or feed file
+func isSearchTerm(input string) bool {
+	return !podcast.IsID(input) && !podcast.IsFeedURL(input) && !podcast.IsFeedFile(input)
+}
 
-			if audioURL == "" {
+// runBatch resolves and downloads each input in turn, reporting success or
+// failure per input and continuing past errors. With opts.parallelFeeds,
+// feeds further down the list load while earlier ones download. It returns
+// the failure count.
+func runBatch(inputs []string, baseDir string, provider podcast.SearchProvider, opts options) int {
+	budget := &sizeBudget{limit: opts.maxTotalSize}
+	fmt.Printf("Saving to %s\n", baseDir)
+	if caps := rateSummary(opts.download); caps != "" {
+		fmt.Printf("Rate limit: %s\n", caps)
+	}
+	var checks *feedChecks
+	if opts.update {
+		checks = loadFeedChecks(baseDir, opts)
+	}
+	ready := resolveAll(inputs, provider, opts, checks)
+	failed, skipped, unchanged := 0, 0, 0
+	var noTrailer []string // with opts.trailers, the podcasts that have none
+	var retries []feedRetry
+	for i, input := range inputs {
+		fmt.Printf("==> %s\n", input)
+		r := <-ready[i]
+		info, episodes, err := r.info, r.episodes, r.err
+		if !r.unchangedSince.IsZero() {
+			fmt.Printf("  - %s: no changes since %s\n", info.Name, r.unchangedSince.Local().Format("2006-01-02 15:04"))
+			unchanged++
+			continue
+		}
+		if err == nil && len(opts.languages) > 0 {
+			switch {
+			case info.Language == "":
+				fmt.Printf("  ! %s declares no language; downloading anyway\n", info.Name)
+			case !podcast.MatchesLanguage(info.Language, opts.languages):
+				fmt.Printf("  - skipping %s: language %s\n", info.Name, info.Language)
+				skipped++
 				continue
 			}
-
-			var pubDate time.Time
-			if item.PublishedParsed != nil {
-				pubDate = *item.PublishedParsed
+		}
+		if err == nil && opts.trailers {
+			if len(info.Trailers) == 0 {
+				fmt.Printf("  - skipping %s: no trailer\n", info.Name)
+				noTrailer = append(noTrailer, info.Name)
+				continue
 			}
-
-			duration := ""
-			if item.ITunesExt != nil {
-				duration = item.ITunesExt.Duration
+			episodes = info.Trailers
+		}
+		if err == nil {
+			var future, older, duplicates int
+			if !opts.includeFuture {
+				episodes, future = podcast.DropFuture(episodes, time.Now())
 			}
-
-			episodes = append(episodes, Episode{
-				Index:       i + 1,
-				Title:       item.Title,
-				Description: item.Description,
-				AudioURL:    audioURL,
-				PubDate:     pubDate,
-				Duration:    duration,
-			})
+			if opts.within != nil {
+				episodes, older = podcast.DropBefore(episodes, opts.within.Cutoff(time.Now()))
+			}
+			if opts.dedup {
+				episodes, duplicates = podcast.DedupEpisodes(episodes)
+			}
+			noun := "episode(s)"
+			if opts.trailers {
+				noun = "trailer(s)"
+			}
+			fmt.Printf("  %s: %d %s\n", info.Name, len(episodes), noun)
+			if future > 0 {
+				fmt.Printf("  skipping %d episode(s) dated in the future\n", future)
+			}
+			if older > 0 {
+				fmt.Printf("  skipping %d episode(s) older than %s\n", older, opts.within)
+			}
+			if duplicates > 0 {
+				fmt.Printf("  collapsed %d duplicate episode(s), keeping the newest of each\n", duplicates)
+			}
+			if isSearchTerm(input) {
+				// Show which search match was taken, so it can be checked
+				fmt.Printf("  top match: %s by %s (%s)\n", info.Name, info.Artist, info.FeedURL)
+			}
+			if info.MovedFrom != "" {
+				fmt.Printf("  ! feed moved permanently to %s\n", info.FeedURL)
+			}
+			if info.NewFeedURL != "" {
+				fmt.Printf("  ! the publisher moved this feed to %s; use that URL from now on\n", info.NewFeedURL)
+			}
+			for _, feedURL := range info.MergedFrom {
+				fmt.Printf("  merged episodes from %s\n", feedURL)
+			}
+			if warning := blockWarning(info, episodes); warning != "" {
+				fmt.Printf("  ! %s\n", warning)
+			}
+			if opts.sortBy != "" {
+				podcast.SortEpisodes(episodes, opts.sortBy, opts.reverse)
+			}
+			if opts.newest {
+				if ep, ok := podcast.Newest(episodes); ok {
+					episodes = []podcast.Episode{ep}
+					fmt.Printf("  newest: %s (%s)\n", ep.Title, ep.PubDate.Format("January 2, 2006"))
+				}
+			}
+			if opts.renumber {
+				podcast.Renumber(episodes)
+			}
+			podcast.OrderForDownload(episodes, opts.downloadOrder)
+			err = downloadEpisodes(info, episodes, baseDir, opts, budget)
 		}
-
-		if len(episodes) == 0 {
-			return errorMsg{err: fmt.Errorf("no downloadable episodes found")}
+		if err != nil {
+			var partial *episodesFailed
+			if errors.As(err, &partial) {
+				retries = append(retries, feedRetry{input, info, partial.episodes, partial.total})
+			}
+			fmt.Printf("  ✗ %s: %v\n", input, err)
+			failed++
+			continue
+		}
+		fmt.Printf("  ✓ %s\n", info.Name)
+		if !budget.exhausted {
+			checks.record(input, info)
 		}
+	}
+	failed -= retryFeeds(retries, baseDir, opts, budget)
 
-		return podcastLoadedMsg{info: info, episodes: episodes}
+	summary := fmt.Sprintf("\n%d succeeded", len(inputs)-failed-skipped-unchanged-len(noTrailer))
+	if unchanged > 0 {
+		summary += fmt.Sprintf(", %d unchanged", unchanged)
+	}
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped (language)", skipped)
 	}
+	if len(noTrailer) > 0 {
+		summary += fmt.Sprintf(", %d without a trailer", len(noTrailer))
+	}
+	fmt.Printf("%s, %d failed\n", summary, failed)
+	if len(noTrailer) > 0 {
+		fmt.Println("No trailer:")
+		for _, name := range noTrailer {
+			fmt.Printf("  - %s\n", name)
+		}
+	}
+	return failed
 }
 
 func main() {
 	// Define flags
-	baseDir := flag.String("o", ".", "Base directory where the podcast folder will be created")
+	baseDir := flag.String("o", "", "Base directory where the podcast folder will be created (default: $"+outputDirEnv+", else a Podcasts folder in the music directory ($XDG_MUSIC_DIR or ~/Music) when there is one, else the current directory; -o . keeps the current directory)")
 	indexFlag := flag.String("index", "apple", "Search provider: 'apple' (default) or 'podcastindex'")
+	parallelFeeds := flag.Int("parallel-feeds", 1, "With --stdin, how many feeds to load at once while earlier ones download")
+	startJitter := flag.Duration("start-jitter", 100*time.Millisecond, "Longest random pause between starting concurrent requests (--parallel-feeds, size checks), to spare rate-limited hosts; 0 starts them together")
+	stdinFlag := flag.Bool("stdin", false, "Read feed URLs, podcast IDs or search terms from stdin, one per line (implies --download-all)")
+	newest := flag.Bool("newest", false, "Download only the most recent episode, without the interactive picker")
+	grabLatest := flag.Bool("grab-latest", false, "Search, take the top match and download its newest episode (same as --newest)")
+	downloadAll := flag.Bool("download-all", false, "Download every episode without the interactive picker")
+	trailers := flag.Bool("trailers", false, "Download only each podcast's trailer clips (podcast:trailer), without the interactive picker; with --stdin, samples many shows quickly")
+	themeFlag := flag.String("theme", "", "Color theme: "+strings.Join(themeNames, ", ")+" (default, or mono when NO_COLOR is set)")
+	noColor := flag.Bool("no-color", false, "Disable colors (same as --theme mono)")
+	maxRate := flag.String("max-rate", "", "Cap the combined download rate, in bytes per second, e.g. 2M")
+	maxRatePerFile := flag.String("max-rate-per-file", "", "Cap each file's download rate, in bytes per second, e.g. 500K")
+	bufferSize := flag.String("buffer-size", "32K", "Read and write buffer per download; larger buffers, e.g. 1M, use fewer system calls on fast links")
+	maxTotalSize := flag.String("max-total-size", "", "Stop starting new downloads once this much has been written, e.g. 500M or 2G")
+	saveFeed := flag.Bool("save-feed", false, "Save the podcast's RSS feed as "+podcast.FeedFilename+" in the podcast folder")
+	checksumManifest := flag.Bool("checksum-manifest", false, "Record the SHA-256 of each downloaded file in "+checksumFile+" in the podcast folder, for --verify and sha256sum -c")
+	flat := flag.Bool("flat", false, "Save every episode directly in the -o folder instead of a folder per podcast; add --podcast-prefix to keep names from different shows apart")
+	podcastPrefix := flag.Bool("podcast-prefix", false, "Start file names with the podcast name, e.g. The Daily - 001 - Title.mp3")
+	flatSingle := flag.Bool("no-subfolder-if-single", false, "Save a single-episode download directly in the -o folder, named after the podcast, instead of a podcast subfolder")
+	update := flag.Bool("update", false, "In batch runs (--download-all, --newest, --stdin), skip feeds the server says haven't changed since their last complete download into the same folder (with If-None-Match and If-Modified-Since)")
+	languageFlag := flag.String("language", "", "In batch runs (--download-all, --newest, --stdin), skip feeds whose language isn't one of these comma-separated codes, e.g. en or en-US,fr")
+	within := flag.String("within", "", "Keep only episodes published within this window back from now: a count and a unit, h, d, w, mo or y, e.g. 30d or 6mo")
+	dedupEpisodes := flag.Bool("dedup-episodes", false, "Collapse episodes the feed lists more than once (same audio URL, or same title and duration), keeping the newest")
+	includeFuture := flag.Bool("include-future", false, "Keep episodes dated in the future, which are skipped by default as scheduled or placeholder entries")
+	mergeListings := flag.Bool("merge-listings", false, "Also load the show's other Apple or Podcast Index listings and add the episodes missing from its feed (one more search and feed fetch)")
+	byAuthor := flag.String("by-author", "", "Only keep search results whose author contains this name; searches for the name when no query is given")
+	maxFilenameLength := flag.Int("max-filename-length", podcast.DefaultMaxFilenameLength, "Longest episode title, in characters, kept in file names; longer titles are cut at a word boundary")
+	prependDate := flag.Bool("prepend-date", false, "Start file names with the episode's publish date, e.g. 2023-05-12 - 001 - Title.mp3")
+	renumber := flag.Bool("renumber", false, "Number the downloaded episodes 1..N in list order instead of keeping their feed position")
+	overwriteTags := flag.Bool("overwrite-tags-only", false, "Rewrite the tags of selected episodes that are already downloaded instead of leaving them untouched")
+	showArtist := flag.Bool("show-artist", false, "Tag every episode with the show's author as artist, even when the episode names its own")
+	embedArtwork := flag.Bool("embed-artwork", false, "Embed the podcast's cover art in the MP3 tags")
+	cover := flag.Bool("cover", false, "Save the podcast's cover, at the largest size available, as "+coverFile+" in its folder, where media servers and file managers look for it")
+	artworkJPEG := flag.Bool("artwork-jpeg", false, "Convert cover art that older players can't show, such as WebP, to JPEG before embedding (implies --embed-artwork)")
+	id3Version := flag.String("id3-version", "2.3", "ID3 tag version to write, 2.3 (read by more players, including older car stereos) or 2.4")
+	tagPeople := flag.Bool("tag-people", false, "Add the hosts and guests a feed lists with podcast:person to a "+podcast.PeopleTagDescription+" tag")
+	columns := flag.String("columns", "", "Episode details to show beside the titles in the picker, comma-separated: index, date, duration, size and explicit, or none (default: the last set chosen with c, else index,date,duration)")
+	sortBy := flag.String("sort-by", "", "Order episodes by date, title or duration (default: feed order)")
+	reverse := flag.Bool("reverse", false, "Reverse the --sort-by order")
+	downloadOrder := flag.String("download-order", "selection", "Order to fetch episodes in: newest, oldest or selection (as listed); numbering is unaffected")
+	selectRegex := flag.String("select-regex", "", "Start the episode picker with the episodes whose title matches this regular expression selected")
+	retag := flag.String("retag", "", "Rewrite the tags of the MP3 files in this folder from the podcast's current feed, then exit")
+	feedTimeout := flag.Duration("feed-timeout", 2*time.Minute, "Give up on a feed that hasn't fully arrived after this long; 0 waits indefinitely")
+	retries := flag.Int("retries", 1, "In batch runs, how many more passes to make over the episodes that failed, after a pause, before giving up on them; 0 reports failures right away")
+	downloadTimeout := flag.Duration("download-timeout", time.Minute, "Resume a download that receives no data for this long, failing after 3 retries; 0 waits indefinitely")
+	minSize := flag.String("min-size", "10K", "Treat downloads smaller than this as failed and delete them; 0 accepts any size")
+	onExists := flag.String("on-exists", "skip", "What to do with an episode file that already exists: skip, check (ask the server for its size and resume a partial file) or overwrite")
+	renameFromTags := flag.String("rename-from-tags", "", "Rename the MP3 files in this folder after their ID3 title and track number, then exit")
+	verify := flag.String("verify", "", "Check that the downloads recorded in the history under this folder are still intact, then exit")
+	repair := flag.Bool("repair", false, "With --verify, download missing or truncated files again")
+	history := flag.Bool("history", false, fmt.Sprintf("Print the last %d downloaded episodes and exit", historyShown))
+	resolveID := flag.String("resolve-id", "", "Print the Apple Podcasts ID of a feed URL and exit")
+	audioExtMap := flag.String("audio-ext-map", "", "Force the file extension for enclosure MIME types the feed or server mislabel, as comma-separated type=.ext pairs, e.g. audio/x-m4a=.m4a")
+	country := flag.String("country", "", "Apple Podcasts store to search, as a two-letter country code (default: from the system locale)")
+	artworkOnly := flag.Bool("artwork-only", false, "Save only the cover image of each show, named after it, instead of episodes: every match of a search, every feed of an .opml file, or the podcast a feed URL or ID names; then exit")
+	artworkSize := flag.String("artwork-size", "", "Width in pixels of the Apple artwork to use, e.g. 1200, or original (default: the 600px image Apple lists)")
+	serve := flag.String("serve", "", "Serve a JSON API on this address, e.g. :8080, to search, list episodes and queue downloads into the output directory from scripts or a web UI, instead of the TUI")
+	recent := flag.Bool("recent", false, "Browse the episodes most recently published across Podcast Index and pick any to download (needs Podcast Index credentials)")
+	feedOnly := flag.Bool("feed-only", false, "Print the podcast's show-level metadata (title, author, language, categories, episode count, update frequency...) and exit, without listing or downloading episodes")
+	jsonOutput := flag.Bool("json", false, "With --feed-only, print the metadata as JSON")
+	listProviders := flag.Bool("list-providers", false, "Print which search providers are available, checking the Podcast Index credentials, and exit")
+	piBaseURL := flag.String("pi-base-url", "", "Podcast Index API base URL (default $PODCASTINDEX_BASE_URL or "+podcast.DefaultPodcastIndexBaseURL+")")
 
 	// Custom usage message
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <podcast_id_or_search_query>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] [podcast_id_or_search_query]\n\n", os.Args[0])
 		fmt.Fprintln(os.Stderr, "Flags:")
 		flag.PrintDefaults()
 		fmt.Fprintln(os.Stderr, "\nExamples:")
 		fmt.Fprintln(os.Stderr, "  podcastdownload -o ~/Music \"the daily\"")
 		fmt.Fprintln(os.Stderr, "  podcastdownload 1200361736")
+		fmt.Fprintln(os.Stderr, "  podcastdownload ~/Podcasts/\"The Daily\"/feed.xml")
+		fmt.Fprintln(os.Stderr, "  podcastdownload --grab-latest \"the daily\"")
 		fmt.Fprintln(os.Stderr, "  podcastdownload --index podcastindex \"france inter\"")
+		fmt.Fprintln(os.Stderr, "  podcastdownload --select-regex \"(?i)interview\" \"the daily\"")
+		fmt.Fprintln(os.Stderr, "  podcastdownload --by-author \"Ira Glass\" \"american life\"")
+		fmt.Fprintln(os.Stderr, "  cat feeds.txt | podcastdownload --stdin --download-all")
+		fmt.Fprintln(os.Stderr, "  podcastdownload --retag ~/Podcasts/\"The Daily\" 1200361736")
+		fmt.Fprintln(os.Stderr, "  podcastdownload --verify ~/Podcasts --repair")
+		fmt.Fprintln(os.Stderr, "  podcastdownload --resolve-id https://feeds.example.com/show.xml")
+		fmt.Fprintln(os.Stderr, "  podcastdownload --feed-only --json https://feeds.example.com/show.xml")
 		fmt.Fprintln(os.Stderr, "\nPodcast Index:")
 		fmt.Fprintln(os.Stderr, "  To use Podcast Index, set these environment variables:")
 		fmt.Fprintln(os.Stderr, "    PODCASTINDEX_API_KEY=your_key")
 		fmt.Fprintln(os.Stderr, "    PODCASTINDEX_API_SECRET=your_secret")
+		fmt.Fprintln(os.Stderr, "    PODCASTINDEX_BASE_URL=https://your.mirror/api/1.0  (optional)")
 		fmt.Fprintln(os.Stderr, "  Get free API keys at: https://api.podcastindex.org")
 	}
 
 	flag.Parse()
+	*newest = *newest || *grabLatest
+
+	if *history {
+		if err := printHistory(os.Stdout, historyShown); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *feedTimeout < 0 || *downloadTimeout < 0 || *startJitter < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --feed-timeout, --download-timeout and --start-jitter can't be negative\n")
+		os.Exit(1)
+	}
+	podcast.FeedTimeout = *feedTimeout
+
+	// Pick the Apple store: flag, then the system locale
+	if *country != "" {
+		code, err := parseCountry(*country)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --country: %v\n", err)
+			os.Exit(1)
+		}
+		podcast.AppleCountry = code
+	} else {
+		podcast.AppleCountry = localeCountry()
+	}
+
+	if *audioExtMap != "" {
+		overrides, err := podcast.ParseExtensionMap(*audioExtMap)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --audio-ext-map: %v\n", err)
+			os.Exit(1)
+		}
+		podcast.ExtensionOverrides = overrides
+	}
+
+	if *maxFilenameLength < 10 || *maxFilenameLength > 200 {
+		fmt.Fprintf(os.Stderr, "Error: --max-filename-length: invalid length %d (expected 10 to 200)\n", *maxFilenameLength)
+		os.Exit(1)
+	}
+	podcast.MaxFilenameLength = *maxFilenameLength
+
+	if *artworkSize != "" {
+		size, err := podcast.ParseArtworkSize(*artworkSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --artwork-size: %v\n", err)
+			os.Exit(1)
+		}
+		podcast.AppleArtworkSize = size
+	}
+
+	if *resolveID != "" {
+		id, err := podcast.ResolveAppleID(*resolveID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(id)
+		return
+	}
+
+	if *baseDir == "" {
+		*baseDir = defaultOutputDir()
+	}
+	dir, err := expandPath(*baseDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -o: %v\n", err)
+		os.Exit(1)
+	}
+	*baseDir = dir
+
+	// Modes that save into the output directory check it first, so a
+	// read-only one is reported before any searching or picking
+	requireWritable := func() {
+		if err := checkWritable(*baseDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -o: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Resolve the Podcast Index endpoint: flag, then environment, then default
+	rawBaseURL := *piBaseURL
+	if rawBaseURL == "" {
+		rawBaseURL = os.Getenv("PODCASTINDEX_BASE_URL")
+	}
+	if rawBaseURL != "" {
+		base, err := parseBaseURL(rawBaseURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		podcast.PodcastIndexBaseURL = base
+	}
+
+	if *listProviders {
+		os.Exit(printProviders(os.Stdout))
+	}
+
+	opts := options{
+		saveFeed:      *saveFeed,
+		checksums:     *checksumManifest,
+		reverse:       *reverse,
+		flatSingle:    *flatSingle,
+		flat:          *flat,
+		podcastPrefix: *podcastPrefix,
+		renumber:      *renumber,
+		byAuthor:      *byAuthor,
+		retagExisting: *overwriteTags,
+		newest:        *newest,
+		trailers:      *trailers,
+		parallelFeeds: *parallelFeeds,
+		retries:       *retries,
+		prependDate:   *prependDate,
+		mergeListings: *mergeListings,
+		includeFuture: *includeFuture,
+		dedup:         *dedupEpisodes,
+		update:        *update,
+		startJitter:   *startJitter,
+		embedArtwork:  *embedArtwork || *artworkJPEG,
+		cover:         *cover,
+		artworkJPEG:   *artworkJPEG,
+		tags:          podcast.TagOptions{ShowArtist: *showArtist, People: *tagPeople},
+	}
+
+	if *sortBy != "" {
+		key, err := podcast.ParseSortKey(*sortBy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --sort-by: %v\n", err)
+			os.Exit(1)
+		}
+		opts.sortBy = key
+	} else if *reverse {
+		opts.sortBy = podcast.SortDate
+	}
+
+	if *languageFlag != "" {
+		languages, err := podcast.ParseLanguages(*languageFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --language: %v\n", err)
+			os.Exit(1)
+		}
+		opts.languages = languages
+	}
+
+	if *within != "" {
+		window, err := podcast.ParseWindow(*within)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --within: %v\n", err)
+			os.Exit(1)
+		}
+		opts.within = window
+	}
+
+	podcasts, err := loadPodcastConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: per-podcast settings: %v\n", err)
+		os.Exit(1)
+	}
+	opts.podcasts = podcasts
+
+	// --columns wins over the columns last chosen with c
+	opts.columns = defaultColumns
+	saved, err := loadSettings()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: settings: %v\n", err)
+		os.Exit(1)
+	}
+	if *columns == "" {
+		*columns = saved.Columns
+	}
+	if *columns != "" {
+		cols, err := parseColumns(*columns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --columns: %v\n", err)
+			os.Exit(1)
+		}
+		opts.columns = cols
+	}
+
+	version, err := podcast.ParseID3Version(*id3Version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --id3-version: %v\n", err)
+		os.Exit(1)
+	}
+	opts.tags.Version = version
+
+	if *parallelFeeds < 1 {
+		fmt.Fprintf(os.Stderr, "Error: --parallel-feeds must be at least 1\n")
+		os.Exit(1)
+	}
+	if *retries < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --retries must be 0 or more\n")
+		os.Exit(1)
+	}
+
+	policy, err := podcast.ParseExistsPolicy(*onExists)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --on-exists: %v\n", err)
+		os.Exit(1)
+	}
+	opts.download.OnExists = policy
+
+	order, err := podcast.ParseDownloadOrder(*downloadOrder)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --download-order: %v\n", err)
+		os.Exit(1)
+	}
+	opts.downloadOrder = order
+
+	if *selectRegex != "" {
+		re, err := regexp.Compile(*selectRegex)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --select-regex: %v\n", err)
+			os.Exit(1)
+		}
+		opts.selectRegex = re
+	}
+
+	// Pick the theme: explicit flags win, then the NO_COLOR convention
+	themeName := *themeFlag
+	if *noColor {
+		themeName = "mono"
+	} else if themeName == "" && os.Getenv("NO_COLOR") != "" {
+		themeName = "mono"
+	}
+	t, err := themeByName(themeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --theme: %v\n", err)
+		os.Exit(1)
+	}
+	opts.theme = t
+
+	if *maxRate != "" {
+		bytesPerSec, err := parseSize(*maxRate)
+		if err != nil || bytesPerSec <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --max-rate: invalid rate %q\n", *maxRate)
+			os.Exit(1)
+		}
+		opts.download.Limiter = podcast.NewRateLimiter(bytesPerSec)
+	}
+	if *maxRatePerFile != "" {
+		bytesPerSec, err := parseSize(*maxRatePerFile)
+		if err != nil || bytesPerSec <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --max-rate-per-file: invalid rate %q\n", *maxRatePerFile)
+			os.Exit(1)
+		}
+		opts.download.MaxRate = bytesPerSec
+	}
+
+	size, err := parseSize(*minSize)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --min-size: %v\n", err)
+		os.Exit(1)
+	}
+	opts.download.MinSize = size
+	opts.download.StallTimeout = *downloadTimeout
+
+	buffer, err := parseSize(*bufferSize)
+	if err != nil || buffer < 1024 || buffer > 64<<20 {
+		fmt.Fprintf(os.Stderr, "Error: --buffer-size: invalid size %q (expected 1K to 64M)\n", *bufferSize)
+		os.Exit(1)
+	}
+	opts.download.BufferSize = int(buffer)
+
+	if *maxTotalSize != "" {
+		size, err := parseSize(*maxTotalSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --max-total-size: %v\n", err)
+			os.Exit(1)
+		}
+		opts.maxTotalSize = size
+	}
 
 	// Parse the index flag
-	var provider SearchProvider
+	var provider podcast.SearchProvider
 	switch strings.ToLower(*indexFlag) {
 	case "podcastindex", "pi":
-		provider = ProviderPodcastIndex
+		provider = podcast.ProviderPodcastIndex
 	default:
-		provider = ProviderApple
+		provider = podcast.ProviderApple
 	}
 
-	// Check if we have arguments left after parsing flags (the search query)
-	if flag.NArg() < 1 {
+	if *serve != "" {
+		requireWritable()
+		if err := runServe(*serve, *baseDir, provider, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --serve: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Batch mode: one input per line on stdin, no TUI
+	if *stdinFlag {
+		requireWritable()
+		inputs, err := readInputs(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read stdin: %v\n", err)
+			os.Exit(1)
+		}
+		if *artworkOnly {
+			if runArtworkOnly(inputs, *baseDir, provider, opts) > 0 {
+				os.Exit(1)
+			}
+			return
+		}
+		if runBatch(inputs, *baseDir, provider, opts) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *renameFromTags != "" {
+		dir, err := expandPath(*renameFromTags)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --rename-from-tags: %v\n", err)
+			os.Exit(1)
+		}
+		if runRenameFromTags(dir) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *verify != "" {
+		dir, err := expandPath(*verify)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --verify: %v\n", err)
+			os.Exit(1)
+		}
+		if runVerify(dir, *repair, opts) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *recent {
+		if !podcast.HasPodcastIndexCredentials() {
+			fmt.Fprintln(os.Stderr, "Error: --recent: Podcast Index API credentials not set (PODCASTINDEX_API_KEY, PODCASTINDEX_API_SECRET)")
+			os.Exit(1)
+		}
+		requireWritable()
+		p := tea.NewProgram(newRecentModel(*baseDir, provider, opts), tea.WithAltScreen())
+		if _, err := p.Run(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Join remaining arguments to form the search query
+	input := strings.TrimSpace(strings.Join(flag.Args(), " "))
+	if input == "" {
+		input = strings.TrimSpace(*byAuthor)
+	}
+
+	// Without a query the TUI asks for one, but the non-interactive modes
+	// would search for nothing
+	if input == "" && (*downloadAll || *newest || *trailers || *artworkOnly || *retag != "" || *feedOnly) {
+		if flag.NArg() > 0 {
+			fmt.Fprintln(os.Stderr, "Error: the search query is empty")
+			fmt.Fprintln(os.Stderr)
+		}
 		flag.Usage()
 		os.Exit(1)
 	}
 
-	// Join remaining arguments to form the search query
-	input := strings.Join(flag.Args(), " ")
+	if *feedOnly {
+		os.Exit(runFeedOnly(input, provider, opts, *jsonOutput))
+	}
+
+	if *artworkOnly {
+		requireWritable()
+		if runArtworkOnly([]string{input}, *baseDir, provider, opts) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *retag != "" {
+		dir, err := expandPath(*retag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --retag: %v\n", err)
+			os.Exit(1)
+		}
+		if runRetag(dir, input, provider, opts) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	requireWritable()
+	if *downloadAll || *newest || *trailers {
+		if runBatch([]string{input}, *baseDir, provider, opts) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Pass the baseDir and provider to initialModel
-	program = tea.NewProgram(initialModel(input, *baseDir, provider), tea.WithAltScreen())
-	if _, err := program.Run(); err != nil {
+	p := tea.NewProgram(initialModel(input, *baseDir, provider, opts), tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"podcastdownload/internal/podcast"
+)
+
+// newNumberInput returns the text field for toggling episodes by number
+func newNumberInput(t theme) textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "  › "
+	ti.PromptStyle = t.selected
+	ti.Placeholder = "1-5,12,20"
+	ti.CharLimit = 256
+	ti.Width = 40
+	ti.Cursor.SetMode(cursor.CursorStatic) // the model doesn't relay blink messages
+	return ti
+}
+
+// openNumberInput switches to the episode number entry screen
+func (m model) openNumberInput() (tea.Model, tea.Cmd) {
+	m.state = stateSelectNumbers
+	m.numberInput.SetValue("")
+	m.numberErr = ""
+	return m, m.numberInput.Focus()
+}
+
+// numberedEpisodes returns the positions in m.episodes of the episodes
+// whose number falls in ranges
+func (m model) numberedEpisodes(ranges []podcast.EpisodeRange) []int {
+	var matches []int
+	for i, ep := range m.episodes {
+		if podcast.InRanges(ranges, ep.Index) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+func (m model) handleNumberInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.numberInput.Blur()
+		m.state = stateSelecting
+		return m, nil
+
+	case "enter":
+		ranges, err := podcast.ParseEpisodeRanges(m.numberInput.Value())
+		if err != nil {
+			m.numberErr = err.Error()
+			return m, nil
+		}
+		matches := m.numberedEpisodes(ranges)
+		if len(matches) == 0 {
+			m.numberErr = "no episodes have those numbers"
+			return m, nil
+		}
+		for _, i := range matches {
+			m.episodes[i].Selected = !m.episodes[i].Selected
+			if m.episodes[i].Selected {
+				m.selectedCount++
+			} else {
+				m.selectedCount--
+			}
+		}
+		m.numberInput.Blur()
+		m.state = stateSelecting
+		m.notice = fmt.Sprintf("Toggled %d episode(s) by number", len(matches))
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.numberInput, cmd = m.numberInput.Update(msg)
+	m.numberErr = ""
+	return m, cmd
+}
+
+func (m model) viewNumberInput() string {
+	var b strings.Builder
+
+	b.WriteString("\n")
+	b.WriteString(m.theme.title.Render("Select by Number"))
+	b.WriteString("\n\n")
+	b.WriteString(m.theme.subtitle.Render("  Episode numbers and ranges to toggle, as shown in [brackets]."))
+	b.WriteString("\n\n")
+	b.WriteString(m.numberInput.View())
+	b.WriteString("\n")
+
+	switch {
+	case m.numberErr != "":
+		b.WriteString("\n  " + m.theme.error.Render(m.numberErr) + "\n")
+	case strings.TrimSpace(m.numberInput.Value()) != "":
+		if ranges, err := podcast.ParseEpisodeRanges(m.numberInput.Value()); err == nil {
+			b.WriteString(m.theme.dim.Render(fmt.Sprintf("\n  matches %d episode(s)\n", len(m.numberedEpisodes(ranges)))))
+		}
+	}
+
+	b.WriteString(m.theme.help.Render("\n  enter toggle • esc cancel"))
+
+	return b.String()
+}
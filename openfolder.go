@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type folderOpenedMsg struct {
+	dir string
+	err error
+}
+
+// errNoFileManager means there is no desktop to show a folder on, as over
+// SSH or on a server
+var errNoFileManager = errors.New("no file manager available")
+
+// fileManager returns the command that opens a folder in the system file
+// manager
+func fileManager() (string, error) {
+	var name string
+	switch runtime.GOOS {
+	case "darwin":
+		name = "open"
+	case "windows":
+		name = "explorer"
+	default:
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return "", errNoFileManager
+		}
+		name = "xdg-open"
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		return "", errNoFileManager
+	}
+	return name, nil
+}
+
+// openFolder shows dir in the system file manager, without waiting for it
+func openFolder(dir string) tea.Cmd {
+	return func() tea.Msg {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		name, err := fileManager()
+		if err != nil {
+			return folderOpenedMsg{dir: dir, err: err}
+		}
+		cmd := exec.Command(name, dir)
+		if err := cmd.Start(); err != nil {
+			return folderOpenedMsg{dir: dir, err: err}
+		}
+		go cmd.Wait() // reap it; explorer exits 1 even when it worked
+		return folderOpenedMsg{dir: dir}
+	}
+}
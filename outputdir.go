@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// outputDirEnv names the environment variable that sets the default output
+// directory
+const outputDirEnv = "PODCAST_DOWNLOAD_DIR"
+
+// defaultOutputDir is where podcasts are saved when -o isn't given:
+// $PODCAST_DOWNLOAD_DIR, then a Podcasts folder in the user's music
+// directory when there is one, and otherwise the current directory
+func defaultOutputDir() string {
+	if dir := strings.TrimSpace(os.Getenv(outputDirEnv)); dir != "" {
+		return dir
+	}
+	if music := musicDir(); music != "" {
+		return filepath.Join(music, "Podcasts")
+	}
+	return "."
+}
+
+// checkWritable makes sure files can be created in dir, so that a read-only
+// output directory is reported up front rather than when the first download
+// starts. dir itself is only created then, so a missing one is checked
+// through the nearest folder above it that exists.
+func checkWritable(dir string) error {
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", existing)
+			}
+			break
+		}
+		parent := filepath.Dir(existing)
+		missing := errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR)
+		if !missing || parent == existing {
+			return err
+		}
+		existing = parent
+	}
+
+	f, err := os.CreateTemp(existing, ".podcast-go-write-test-*")
+	if err != nil {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		return fmt.Errorf("%s is not writable: %w", existing, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// musicDir returns the user's existing music directory: $XDG_MUSIC_DIR, as
+// set in the environment or in ~/.config/user-dirs.dirs, or ~/Music. It
+// returns "" when there is none.
+func musicDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	candidates := []string{os.Getenv("XDG_MUSIC_DIR"), userDirsMusic(), filepath.Join(home, "Music")}
+	for _, dir := range candidates {
+		if dir == "" {
+			continue
+		}
+		// user-dirs.dirs sets the music directory to $HOME when it's disabled
+		if dir = filepath.Clean(dir); dir == filepath.Clean(home) {
+			continue
+		}
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return ""
+}
+
+// userDirsMusic reads XDG_MUSIC_DIR from the user-dirs.dirs file that
+// xdg-user-dirs maintains on Linux desktops, or returns "" without one
+func userDirsMusic() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	f, err := os.Open(filepath.Join(configDir, "user-dirs.dirs"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "XDG_MUSIC_DIR=")
+		if ok {
+			return os.ExpandEnv(strings.Trim(value, `"`))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// players are the external players tried, in order, to stream an episode
+// preview. $PODCAST_PLAYER, when set, is used instead.
+var players = [][]string{
+	{"mpv", "--no-video"},
+	{"ffplay", "-nodisp", "-autoexit", "-loglevel", "error"},
+	{"cvlc", "--play-and-exit"},
+}
+
+type playerExitedMsg struct {
+	err error
+}
+
+// findPlayer returns the command line of the first available player
+func findPlayer() ([]string, error) {
+	if custom := strings.Fields(os.Getenv("PODCAST_PLAYER")); len(custom) > 0 {
+		return custom, nil
+	}
+	for _, args := range players {
+		if _, err := exec.LookPath(args[0]); err == nil {
+			return args, nil
+		}
+	}
+	return nil, errors.New("no audio player found (install mpv or ffplay, or set PODCAST_PLAYER)")
+}
+
+// playEpisode streams audioURL through an external player, handing it the
+// terminal until it exits
+func playEpisode(audioURL string) tea.Cmd {
+	args, err := findPlayer()
+	if err != nil {
+		return func() tea.Msg { return playerExitedMsg{err: err} }
+	}
+	args = append(args[:len(args):len(args)], audioURL)
+	return tea.ExecProcess(exec.Command(args[0], args[1:]...), func(err error) tea.Msg {
+		return playerExitedMsg{err: err}
+	})
+}
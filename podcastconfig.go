@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"podcastdownload/internal/podcast"
+)
+
+// podcastConfigFile holds the per-podcast settings, in the user's config
+// directory (~/.config/podcast-go on Linux)
+const podcastConfigFile = "podcasts.json"
+
+// podcastSettings overrides the global options for one podcast
+type podcastSettings struct {
+	Folder         string `json:"folder"`            // folder under the output directory, instead of the podcast's name
+	PrependDate    *bool  `json:"prepend_date"`      // as --prepend-date
+	PodcastPrefix  *bool  `json:"podcast_prefix"`    // as --podcast-prefix
+	MaxRatePerFile string `json:"max_rate_per_file"` // as --max-rate-per-file, e.g. 200K
+
+	maxRate int64 // MaxRatePerFile in bytes per second
+}
+
+// podcastConfig is the per-podcast settings, keyed by feed URL or podcast ID
+type podcastConfig map[string]podcastSettings
+
+// configPath is where the named config file lives
+func configPath(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "podcast-go", name), nil
+}
+
+// loadPodcastConfig reads and checks the per-podcast settings; without a
+// config file there are none
+func loadPodcastConfig() (podcastConfig, error) {
+	path, err := configPath(podcastConfigFile)
+	if err != nil {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var raw podcastConfig
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	cfg := make(podcastConfig, len(raw))
+	for key, s := range raw {
+		if s.Folder != "" {
+			s.Folder = filepath.Clean(s.Folder)
+			if filepath.IsAbs(s.Folder) || s.Folder == ".." || strings.HasPrefix(s.Folder, ".."+string(filepath.Separator)) {
+				return nil, fmt.Errorf("%s: %s: folder %q must be inside the output directory", path, key, s.Folder)
+			}
+		}
+		if s.MaxRatePerFile != "" {
+			if s.maxRate, err = parseSize(s.MaxRatePerFile); err != nil {
+				return nil, fmt.Errorf("%s: %s: invalid max_rate_per_file %q", path, key, s.MaxRatePerFile)
+			}
+		}
+		cfg[configKey(key)] = s
+	}
+	return cfg, nil
+}
+
+// configKey normalizes a feed URL so that case and a trailing slash don't
+// matter; podcast IDs are unchanged
+func configKey(s string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s), "/"))
+}
+
+// lookup finds the settings of a loaded podcast, by its feed URL, the URL
+// it moved from, or its ID
+func (c podcastConfig) lookup(info podcast.PodcastInfo) (podcastSettings, bool) {
+	for _, key := range []string{info.FeedURL, info.MovedFrom, info.ID} {
+		if key == "" {
+			continue
+		}
+		if s, ok := c[configKey(key)]; ok {
+			return s, true
+		}
+	}
+	return podcastSettings{}, false
+}
+
+// forPodcast applies the podcast's own settings, if it has any, over the
+// global options
+func (o options) forPodcast(info podcast.PodcastInfo) options {
+	s, ok := o.podcasts.lookup(info)
+	if !ok {
+		return o
+	}
+	if s.Folder != "" {
+		o.folder = s.Folder
+	}
+	if s.PrependDate != nil {
+		o.prependDate = *s.PrependDate
+	}
+	if s.PodcastPrefix != nil {
+		o.podcastPrefix = *s.PodcastPrefix
+	}
+	if s.maxRate > 0 {
+		o.download.MaxRate = s.maxRate
+	}
+	return o
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"podcastdownload/internal/podcast"
+)
+
+// printProviders reports which search providers can be used, checking the
+// Podcast Index credentials with a request. It returns 1 when credentials
+// are set but rejected.
+func printProviders(w io.Writer) int {
+	store := podcast.AppleCountry
+	if store == "" {
+		store = "Apple's default, US"
+	}
+	fmt.Fprintf(w, "Apple Podcasts: available (store: %s)\n", store)
+
+	status := 0
+	combined := false
+	switch {
+	case !podcast.HasPodcastIndexCredentials():
+		fmt.Fprintln(w, "Podcast Index:  not configured (set PODCASTINDEX_API_KEY and PODCASTINDEX_API_SECRET)")
+	default:
+		fmt.Fprintf(w, "Podcast Index:  credentials from the environment, key %s, at %s\n", maskKey(podcast.PodcastIndexKey()), podcast.PodcastIndexBaseURL)
+		if err := podcast.CheckPodcastIndex(); err != nil {
+			fmt.Fprintf(w, "                ✗ %v\n", err)
+			status = 1
+		} else {
+			fmt.Fprintln(w, "                ✓ credentials accepted")
+			combined = true
+		}
+	}
+
+	if combined {
+		fmt.Fprintln(w, "\nSearches query Apple and Podcast Index together.")
+	} else {
+		fmt.Fprintln(w, "\nSearches query Apple only.")
+	}
+	return status
+}
+
+// maskKey shows only the ends of an API key
+func maskKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[:4] + "…" + key[len(key)-4:]
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"podcastdownload/internal/podcast"
+)
+
+// recentPageSize is how many recent episodes each page loads
+const recentPageSize = 100
+
+// recentInfo stands in for the podcast on the selection screen while
+// browsing recent episodes, which span many podcasts
+var recentInfo = podcast.PodcastInfo{Name: "Recent episodes", Artist: "Podcast Index"}
+
+// recentBrowse is the state of browsing Podcast Index's recent episodes
+// with --recent
+type recentBrowse struct {
+	podcasts map[string]podcast.PodcastInfo // each episode's podcast, by audio URL
+	before   int64                          // cursor of the next page; 0 once there are no more
+	loading  bool
+}
+
+type recentLoadedMsg struct {
+	episodes []podcast.RecentEpisode
+	before   int64
+	err      error
+}
+
+// newRecentModel starts the TUI on the recent episodes instead of a search
+func newRecentModel(baseDir string, provider podcast.SearchProvider, opts options) model {
+	m := initialModel("", baseDir, provider, opts)
+	m.state = stateLoading
+	m.loadingMsg = "Loading recent episodes from Podcast Index..."
+	m.recent = &recentBrowse{podcasts: make(map[string]podcast.PodcastInfo), loading: true}
+	return m
+}
+
+// loadRecent fetches the page of recent episodes published before the
+// cursor, or the newest page for 0
+func loadRecent(before int64) tea.Cmd {
+	return func() tea.Msg {
+		episodes, next, err := podcast.RecentEpisodes(recentPageSize, before)
+		return recentLoadedMsg{episodes: episodes, before: next, err: err}
+	}
+}
+
+// addRecent appends a page of recent episodes to the list, numbering them
+// on from the ones already shown
+func (m model) addRecent(msg recentLoadedMsg) (tea.Model, tea.Cmd) {
+	m.recent.loading = false
+	if msg.err != nil {
+		if len(m.episodes) == 0 {
+			m.state = stateError
+			m.errorMsg = msg.err.Error()
+			m.errorHint = errorHint(msg.err)
+		} else {
+			m.notice = "Couldn't load more: " + msg.err.Error()
+		}
+		return m, nil
+	}
+
+	added := 0
+	for _, r := range msg.episodes {
+		if _, seen := m.recent.podcasts[r.AudioURL]; seen {
+			continue
+		}
+		r.Index = len(m.episodes) + 1
+		m.recent.podcasts[r.AudioURL] = r.Podcast
+		m.episodes = append(m.episodes, r.Episode)
+		added++
+	}
+	m.recent.before = msg.before
+
+	if m.state == stateLoading {
+		m.state = stateSelecting
+		m.podcastInfo = recentInfo
+		m.cursor, m.offset = 0, 0
+	} else {
+		m.notice = fmt.Sprintf("Loaded %d more episode(s)", added)
+	}
+	if m.sortBy != "" {
+		podcast.SortEpisodes(m.episodes, m.sortBy, m.sortReverse)
+	}
+	return m, nil
+}
+
+// loadMoreRecent fetches the next page of recent episodes, if there is one
+func (m model) loadMoreRecent() (tea.Model, tea.Cmd) {
+	if m.recent == nil || m.recent.loading {
+		return m, nil
+	}
+	if m.recent.before == 0 {
+		m.notice = "No older episodes"
+		return m, nil
+	}
+	m.recent.loading = true
+	m.notice = "Loading older episodes..."
+	return m, loadRecent(m.recent.before)
+}
+
+// layout places each recent episode in its own podcast's folder
+// under baseDir
+func (r *recentBrowse) layout(baseDir string, opts options) layout {
+	l := layout{name: podcast.SanitizeFilename(recentInfo.Name), dir: baseDir, podcasts: make(map[string]layout)}
+	for audioURL, info := range r.podcasts {
+		l.podcasts[audioURL] = newLayout(info, baseDir, 0, opts)
+	}
+	return l
+}
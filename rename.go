@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"podcastdownload/internal/podcast"
+)
+
+// runRenameFromTags renames the MP3 files in dir after their ID3 title and
+// track number, as downloads are named, reporting each file. Like a
+// download, a rename never replaces a file that is already there. It
+// returns the number of files that couldn't be renamed.
+func runRenameFromTags(dir string) int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	files, renamed, failed := 0, 0, 0
+	for _, entry := range entries {
+		if entry.IsDir() || !podcast.IsMP3(entry.Name()) {
+			continue
+		}
+		files++
+
+		ep, err := podcast.ReadTags(filepath.Join(dir, entry.Name()))
+		switch {
+		case err != nil:
+			fmt.Printf("  ✗ %s: %v\n", entry.Name(), err)
+			failed++
+			continue
+		case ep.Title == "" || ep.Index == 0:
+			fmt.Printf("  ? %s: no title or track number tag\n", entry.Name())
+			failed++
+			continue
+		}
+
+		name := podcast.EpisodeFilename(dir, "", ep)
+		if name == entry.Name() {
+			continue
+		}
+		target := filepath.Join(dir, name)
+		if _, err := os.Stat(target); err == nil {
+			fmt.Printf("  ✗ %s: %s already exists\n", entry.Name(), name)
+			failed++
+			continue
+		}
+		if err := os.Rename(filepath.Join(dir, entry.Name()), target); err != nil {
+			fmt.Printf("  ✗ %s: %v\n", entry.Name(), err)
+			failed++
+			continue
+		}
+		renamed++
+		fmt.Printf("  ✓ %s → %s\n", entry.Name(), name)
+	}
+
+	fmt.Printf("\n%d file(s), %d renamed, %d failed\n", files, renamed, failed)
+	return failed
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"podcastdownload/internal/podcast"
+)
+
+// resumeState records the episodes of a download that haven't finished, so
+// a run cut short by a crash or reboot can pick up where it stopped
+type resumeState struct {
+	Podcast string   `json:"podcast"`
+	FeedURL string   `json:"feed_url"`
+	Pending []string `json:"pending"` // audio URLs not yet fully downloaded
+}
+
+// resumePath is the resume file for output, hidden in its folder and named
+// after the podcast since a flat folder holds several
+func (l layout) resumePath() string {
+	return filepath.Join(l.dir, "."+l.name+".resume.json")
+}
+
+// saveResume writes the episodes still pending to path, or removes the
+// file once none are. Resume files are best-effort, like the history.
+func saveResume(path string, info podcast.PodcastInfo, pending []podcast.Episode) {
+	if len(pending) == 0 {
+		os.Remove(path)
+		return
+	}
+	state := resumeState{Podcast: info.Name, FeedURL: info.FeedURL}
+	for _, ep := range pending {
+		state.Pending = append(state.Pending, ep.AudioURL)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0644)
+}
+
+// findResume looks for an interrupted download of info under baseDir and
+// returns its pending episodes, along with the resume file's path. A lone
+// episode may have been saved outside the podcast folder, so both places
+// are checked.
+func findResume(info podcast.PodcastInfo, baseDir string, episodes []podcast.Episode, opts options) ([]podcast.Episode, string) {
+	for _, count := range []int{len(episodes), 1} {
+		path := newLayout(info, baseDir, count, opts).resumePath()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var state resumeState
+		if json.Unmarshal(data, &state) != nil {
+			continue
+		}
+
+		var pending []podcast.Episode
+		for _, ep := range episodes {
+			if slices.Contains(state.Pending, ep.AudioURL) {
+				pending = append(pending, ep)
+			}
+		}
+		if len(pending) > 0 {
+			return pending, path
+		}
+	}
+	return nil, ""
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"podcastdownload/internal/podcast"
+)
+
+// runRetag rewrites the tags of the MP3 files in dir from the current
+// metadata of the podcast given by input, reporting each file. It returns
+// the number of files that couldn't be matched or updated.
+func runRetag(dir, input string, provider podcast.SearchProvider, opts options) int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	info, episodes, err := podcast.Resolve(input, provider, opts.byAuthor)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("==> %s: %d episode(s)\n", info.Name, len(episodes))
+	opts.tags, err = withArtwork(opts.tags, info, filepath.Join(dir, coverFile), opts)
+	if err != nil {
+		fmt.Printf("  ! %v\n", err)
+	}
+
+	files, matched, updated := 0, 0, 0
+	for _, entry := range entries {
+		if entry.IsDir() || !podcast.IsMP3(entry.Name()) {
+			continue
+		}
+		files++
+
+		ep, ok := podcast.MatchEpisode(entry.Name(), episodes)
+		if !ok {
+			fmt.Printf("  ? %s: no matching episode\n", entry.Name())
+			continue
+		}
+		matched++
+
+		path := filepath.Join(dir, entry.Name())
+		podcast.FillDuration(&ep, path)
+		if err := podcast.AddID3Tags(path, ep, info, opts.tags); err != nil {
+			fmt.Printf("  ✗ %s: %v\n", entry.Name(), err)
+			continue
+		}
+		refreshChecksum(path) // a manifest entry would no longer match
+		updated++
+		fmt.Printf("  ✓ %s → [%d] %s\n", entry.Name(), ep.Index, ep.Title)
+	}
+
+	fmt.Printf("\n%d file(s), %d matched, %d updated\n", files, matched, updated)
+	return files - updated
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"podcastdownload/internal/podcast"
+)
+
+// fatalDownloadError reports whether a failed download means the rest would
+// fail too: the output folder is full, read-only or not writable
+func fatalDownloadError(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EROFS) || errors.Is(err, fs.ErrPermission)
+}
+
+// unfinished are the selected episodes the last pass didn't download: the
+// failures, and those never started when an error stopped the pass.
+// Episodes skipped for --max-total-size are left out, as the cap still holds.
+func (m model) unfinished() []podcast.Episode {
+	failed := make(map[string]bool)
+	for _, r := range m.results {
+		if r.status == statusFailed {
+			failed[r.url] = true
+		}
+	}
+	var episodes []podcast.Episode
+	for i, ep := range m.getSelectedEpisodes() {
+		if failed[ep.AudioURL] || (m.stopErr != nil && i >= len(m.results)) {
+			episodes = append(episodes, ep)
+		}
+	}
+	return episodes
+}
+
+// retryUnfinished downloads the unfinished episodes again, keeping the
+// results of the episodes already done for the done screen
+func (m model) retryUnfinished() (tea.Model, tea.Cmd) {
+	retry := m.unfinished()
+	for _, r := range m.results {
+		if r.status != statusFailed {
+			m.earlier = append(m.earlier, r)
+		}
+	}
+
+	urls := make(map[string]bool)
+	for _, ep := range retry {
+		urls[ep.AudioURL] = true
+	}
+	for i := range m.episodes {
+		m.episodes[i].Selected = urls[m.episodes[i].AudioURL]
+	}
+	m.selectedCount = len(retry)
+
+	m.state = stateDownloading
+	m.results = nil
+	m.stopErr = nil
+	m.downloadIndex = 0
+	m.downloadTotal = len(retry)
+	m.percent = 0
+	saveResume(m.resumeFile, m.podcastInfo, retry)
+	return m, m.downloadNextCmd()
+}
+
+// retryPassDelay is the pause before each retry pass of a batch, multiplied
+// by the pass number, so transient failures have time to clear
+const retryPassDelay = 30 * time.Second
+
+// episodesFailed is returned by downloadEpisodes when some episodes failed
+type episodesFailed struct {
+	episodes []podcast.Episode
+	total    int // episodes attempted
+}
+
+func (e *episodesFailed) Error() string {
+	return fmt.Sprintf("%d of %d episodes failed", len(e.episodes), e.total)
+}
+
+// feedRetry holds the failed episodes of one batch input
+type feedRetry struct {
+	input    string
+	info     podcast.PodcastInfo
+	episodes []podcast.Episode
+	total    int // episodes the input downloaded, which decides its layout
+}
+
+// retryFeeds downloads the failed episodes of a batch again, in up to
+// opts.retries passes, and lists those that still fail. It returns how
+// many inputs ended up with every episode downloaded.
+func retryFeeds(retries []feedRetry, baseDir string, opts options, budget *sizeBudget) int {
+	recovered := 0
+	for pass := 1; pass <= opts.retries && len(retries) > 0; pass++ {
+		count := 0
+		for _, r := range retries {
+			count += len(r.episodes)
+		}
+		delay := retryPassDelay * time.Duration(pass)
+		fmt.Printf("\nRetrying %d failed episode(s) in %s (pass %d of %d)\n", count, delay, pass, opts.retries)
+		time.Sleep(delay)
+
+		var still []feedRetry
+		for _, r := range retries {
+			fmt.Printf("==> %s\n", r.input)
+			retryOpts := opts
+			retryOpts.saveFeed = false
+			if r.total > 1 {
+				// Keep the episodes in the podcast folder the first pass used
+				retryOpts.flatSingle = false
+			}
+			err := downloadEpisodes(r.info, r.episodes, baseDir, retryOpts, budget)
+			var partial *episodesFailed
+			switch {
+			case err == nil:
+				fmt.Printf("  ✓ %s\n", r.info.Name)
+				recovered++
+			case errors.As(err, &partial):
+				fmt.Printf("  ✗ %s: %v\n", r.input, err)
+				r.episodes = partial.episodes
+				still = append(still, r)
+			default:
+				fmt.Printf("  ✗ %s: %v\n", r.input, err)
+				still = append(still, r)
+			}
+		}
+		retries = still
+	}
+
+	if len(retries) > 0 && opts.retries > 0 {
+		fmt.Println("\nStill failing:")
+		for _, r := range retries {
+			for _, ep := range r.episodes {
+				fmt.Printf("  - %s: %s\n", r.info.Name, ep.Title)
+			}
+		}
+	}
+	return recovered
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"podcastdownload/internal/podcast"
+)
+
+// newSearchInput returns the text field of the search entry screen
+func newSearchInput(t theme) textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "  › "
+	ti.PromptStyle = t.selected
+	ti.Placeholder = "podcast name, Apple ID or feed URL"
+	ti.CharLimit = 512
+	ti.Width = 60
+	ti.Cursor.SetMode(cursor.CursorStatic) // the model doesn't relay blink messages
+	return ti
+}
+
+// openSearchInput switches to the search entry screen, prefilled with the
+// last query
+func (m model) openSearchInput() (tea.Model, tea.Cmd) {
+	m.state = stateSearchInput
+	m.searchInput.SetValue(m.searchQuery)
+	m.searchInput.CursorEnd()
+	return m, m.searchInput.Focus()
+}
+
+func (m model) handleSearchInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		if len(m.searchResults) > 0 {
+			m.state = stateSearchResults
+			return m, nil
+		}
+		return m, tea.Quit
+
+	case "enter":
+		query := strings.TrimSpace(m.searchInput.Value())
+		if query == "" {
+			return m, nil
+		}
+		m.searchInput.Blur()
+		m.state = stateLoading
+
+		switch {
+		case podcast.IsFeedURL(query):
+			m.loadingMsg = "Loading feed..."
+			return m, loadPodcastFromFeed(query, "", "", "")
+		case podcast.IsID(query):
+			m.podcastID = query
+			m.loadingMsg = "Looking up podcast..."
+			return m, loadPodcast(query)
+		}
+
+		m.searchQuery = query
+		m.loadingMsg = searchingMsg(m.searchProvider)
+		return m, searchCmd(query, m.searchProvider)
+	}
+
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewSearchInput() string {
+	var b strings.Builder
+
+	b.WriteString("\n")
+	b.WriteString(m.theme.title.Render("Search Podcasts"))
+	b.WriteString("\n\n")
+	b.WriteString(m.searchInput.View())
+	b.WriteString("\n")
+	b.WriteString(m.theme.dim.Render("\n  Saving to " + m.baseDir))
+	b.WriteString("\n")
+
+	b.WriteString(m.theme.help.Render("\n  enter search • esc back • ctrl+c quit"))
+
+	return b.String()
+}
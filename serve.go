@@ -0,0 +1,292 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"podcastdownload/internal/podcast"
+)
+
+// searchHit is a search result as --serve returns it
+type searchHit struct {
+	ID      string `json:"id,omitempty"`
+	Name    string `json:"name"`
+	Author  string `json:"author"`
+	FeedURL string `json:"feed_url"`
+	Artwork string `json:"artwork,omitempty"`
+	Source  string `json:"source"`
+}
+
+// episodeItem is an episode as --serve lists it; guid or audio_url name it
+// in a download request
+type episodeItem struct {
+	Index     int       `json:"index"`
+	GUID      string    `json:"guid,omitempty"`
+	Title     string    `json:"title"`
+	Published time.Time `json:"published,omitzero"`
+	Duration  string    `json:"duration,omitempty"`
+	AudioURL  string    `json:"audio_url"`
+	Size      int64     `json:"size,omitempty"`
+}
+
+// downloadRequest is the body of POST /downloads
+type downloadRequest struct {
+	Input    string   `json:"input"`    // feed URL, podcast ID or search term
+	Episodes []string `json:"episodes"` // GUIDs or audio URLs; none means all
+	Newest   bool     `json:"newest"`   // only the most recent episode
+}
+
+// serveJob is a queued download and how far it has got
+type serveJob struct {
+	ID       int       `json:"id"`
+	Input    string    `json:"input"`
+	Podcast  string    `json:"podcast"`
+	State    string    `json:"state"` // queued, running, done or failed
+	Episodes int       `json:"episodes"`
+	Finished int       `json:"finished"` // episodes attempted before the current one
+	Current  string    `json:"current,omitempty"`
+	Percent  float64   `json:"percent"` // of the current episode
+	Failed   int       `json:"failed"`
+	Error    string    `json:"error,omitempty"`
+	Queued   time.Time `json:"queued"`
+
+	info     podcast.PodcastInfo
+	selected []podcast.Episode
+}
+
+// server is the state of --serve: the queue and every job it has seen
+type server struct {
+	baseDir  string
+	provider podcast.SearchProvider
+	opts     options
+
+	mu    sync.Mutex
+	jobs  []*serveJob
+	queue chan *serveJob
+}
+
+// runServe serves a small JSON API for driving downloads from scripts or a
+// web UI: searching, loading a podcast's episodes and queueing downloads,
+// which run one at a time into baseDir. It only returns on error.
+func runServe(addr, baseDir string, provider podcast.SearchProvider, opts options) error {
+	s := &server{baseDir: baseDir, provider: provider, opts: opts, queue: make(chan *serveJob, 100)}
+	go s.work()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /search", s.handleSearch)
+	mux.HandleFunc("GET /podcast", s.handlePodcast)
+	mux.HandleFunc("GET /downloads", s.handleJobs)
+	mux.HandleFunc("GET /downloads/{id}", s.handleJob)
+	mux.HandleFunc("POST /downloads", s.handleEnqueue)
+
+	fmt.Printf("Serving on %s, saving to %s\n", addr, baseDir)
+	return http.ListenAndServe(addr, mux)
+}
+
+// writeJSON sends v as the response with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError sends err as {"error": ...}, with 404 when nothing matched
+func writeError(w http.ResponseWriter, status int, err error) {
+	var hidden allHiddenError
+	if errors.Is(err, podcast.ErrNotFound) || errors.As(err, &hidden) {
+		status = http.StatusNotFound
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// GET /search?q=term
+func (s *server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	if q == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing q"))
+		return
+	}
+	results, err := podcast.Search(q, s.provider)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if s.opts.byAuthor != "" {
+		results = podcast.FilterByAuthor(results, s.opts.byAuthor)
+	}
+	hits := []searchHit{}
+	for _, res := range results {
+		hits = append(hits, searchHit{ID: res.ID, Name: res.Name, Author: res.Artist, FeedURL: res.FeedURL, Artwork: res.ArtworkURL, Source: string(res.Source)})
+	}
+	writeJSON(w, http.StatusOK, hits)
+}
+
+// load resolves input and drops the episodes the options hide
+func (s *server) load(input string) (podcast.PodcastInfo, []podcast.Episode, error) {
+	info, episodes, err := podcast.Resolve(input, s.provider, s.opts.byAuthor)
+	if err != nil {
+		return info, nil, err
+	}
+	var future, older int
+	if !s.opts.includeFuture {
+		episodes, future = podcast.DropFuture(episodes, time.Now())
+	}
+	if s.opts.within != nil {
+		episodes, older = podcast.DropBefore(episodes, s.opts.within.Cutoff(time.Now()))
+	}
+	if s.opts.dedup {
+		episodes, _ = podcast.DedupEpisodes(episodes)
+	}
+	if err := hiddenAll(len(episodes), future, older, s.opts.within); err != nil {
+		return info, nil, err
+	}
+	return info, episodes, nil
+}
+
+// GET /podcast?input=feed-url-id-or-search-term
+func (s *server) handlePodcast(w http.ResponseWriter, r *http.Request) {
+	input := r.URL.Query().Get("input")
+	if input == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing input"))
+		return
+	}
+	info, episodes, err := s.load(input)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	items := []episodeItem{}
+	for _, ep := range episodes {
+		items = append(items, episodeItem{Index: ep.Index, GUID: ep.GUID, Title: ep.Title, Published: ep.PubDate, Duration: ep.Duration, AudioURL: ep.AudioURL, Size: ep.ExpectedSize})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"podcast": newFeedProfile(info, episodes), "episodes": items})
+}
+
+// POST /downloads queues the requested episodes of a podcast
+func (s *server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
+	var req downloadRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+		return
+	}
+	if req.Input == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing input"))
+		return
+	}
+	info, episodes, err := s.load(req.Input)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	selected := episodes
+	switch {
+	case req.Newest:
+		selected = nil
+		if ep, ok := podcast.Newest(episodes); ok {
+			selected = []podcast.Episode{ep}
+		}
+	case len(req.Episodes) > 0:
+		wanted := make(map[string]bool)
+		for _, id := range req.Episodes {
+			wanted[id] = true
+		}
+		selected = nil
+		for _, ep := range episodes {
+			if (ep.GUID != "" && wanted[ep.GUID]) || wanted[ep.AudioURL] {
+				selected = append(selected, ep)
+			}
+		}
+	}
+	if len(selected) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("no matching episodes"))
+		return
+	}
+	if s.opts.renumber {
+		podcast.Renumber(selected)
+	}
+	podcast.OrderForDownload(selected, s.opts.downloadOrder)
+
+	s.mu.Lock()
+	job := &serveJob{ID: len(s.jobs) + 1, Input: req.Input, Podcast: info.Name, State: "queued", Episodes: len(selected), Queued: time.Now(), info: info, selected: selected}
+	s.jobs = append(s.jobs, job)
+	status := *job
+	s.mu.Unlock()
+
+	select {
+	case s.queue <- job:
+		writeJSON(w, http.StatusAccepted, status)
+	default:
+		s.update(job, func(j *serveJob) { j.State, j.Error = "failed", "queue full" })
+		writeError(w, http.StatusServiceUnavailable, errors.New("queue full"))
+	}
+}
+
+// GET /downloads
+func (s *server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	jobs := make([]serveJob, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, *j)
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+// GET /downloads/{id}
+func (s *server) handleJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil || id < 1 || id > len(s.jobs) {
+		writeError(w, http.StatusNotFound, errors.New("no such download"))
+		return
+	}
+	writeJSON(w, http.StatusOK, *s.jobs[id-1])
+}
+
+// update changes a job under the lock
+func (s *server) update(job *serveJob, change func(*serveJob)) {
+	s.mu.Lock()
+	change(job)
+	s.mu.Unlock()
+}
+
+// work runs the queued downloads one at a time, with the same tagging,
+// history and checksums as batch downloads
+func (s *server) work() {
+	for job := range s.queue {
+		s.update(job, func(j *serveJob) { j.State = "running" })
+
+		opts := s.opts
+		opts.started = func(i int, path string) {
+			s.update(job, func(j *serveJob) { j.Finished, j.Current, j.Percent = i, filepath.Base(path), 0 })
+		}
+		opts.download.OnProgress = func(percent float64) {
+			s.update(job, func(j *serveJob) { j.Percent = percent })
+		}
+		fmt.Printf("==> %s\n", job.Input)
+		budget := &sizeBudget{limit: opts.maxTotalSize}
+		err := downloadEpisodes(job.info, job.selected, s.baseDir, opts, budget)
+
+		s.update(job, func(j *serveJob) {
+			j.State, j.Finished, j.Current, j.Percent = "done", j.Episodes, "", 0
+			var partial *episodesFailed
+			switch {
+			case errors.As(err, &partial):
+				j.Failed = len(partial.episodes)
+				if j.Failed == j.Episodes {
+					j.State = "failed"
+				}
+			case err != nil:
+				j.State, j.Error = "failed", err.Error()
+			}
+		})
+	}
+}
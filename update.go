@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"podcastdownload/internal/podcast"
+)
+
+// feedChecksFile records, for --update, how each batch input's feed was
+// served at its last complete download; it sits beside the history
+const feedChecksFile = "feeds.json"
+
+// feedCheck is a batch input's feed as of its last complete download
+type feedCheck struct {
+	Input   string    `json:"input"`
+	Mode    string    `json:"mode"` // what was downloaded, as updateMode puts it
+	Dir     string    `json:"dir"`  // the output directory
+	Podcast string    `json:"podcast"`
+	FeedURL string    `json:"feed_url"`
+	Time    time.Time `json:"time"`
+	podcast.Validators
+}
+
+type feedCheckKey struct{ input, mode, dir string }
+
+// feedChecks are the recorded feeds of a --update run, for downloads in
+// mode into dir. The resolving workers read them while finished feeds are
+// recorded, hence the lock.
+type feedChecks struct {
+	mode, dir string
+
+	mu     sync.Mutex
+	checks map[feedCheckKey]feedCheck
+}
+
+// updateMode describes what a batch run downloads, since a feed unchanged
+// since a --newest run still has episodes a --download-all run would want
+func updateMode(opts options) string {
+	mode := "all"
+	switch {
+	case opts.trailers:
+		mode = "trailers"
+	case opts.newest:
+		mode = "newest"
+	}
+	if opts.within != nil {
+		mode += " within " + opts.within.String()
+	}
+	return mode
+}
+
+// loadFeedChecks reads the recorded feeds; a missing or unreadable file
+// just means every feed is loaded in full
+func loadFeedChecks(dir string, opts options) *feedChecks {
+	c := &feedChecks{mode: updateMode(opts), dir: dir, checks: make(map[feedCheckKey]feedCheck)}
+	if abs, err := filepath.Abs(dir); err == nil {
+		c.dir = abs
+	}
+	path, err := dataPath(feedChecksFile)
+	if err != nil {
+		return c
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	var checks []feedCheck
+	if json.Unmarshal(data, &checks) != nil {
+		return c
+	}
+	for _, check := range checks {
+		c.checks[feedCheckKey{check.Input, check.Mode, check.Dir}] = check
+	}
+	return c
+}
+
+// lookup finds the recorded feed of input
+func (c *feedChecks) lookup(input string) (feedCheck, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	check, ok := c.checks[feedCheckKey{input, c.mode, c.dir}]
+	return check, ok
+}
+
+// resolve asks the server whether the feed recorded for input has changed.
+// It reports false when input has to be resolved as usual: it has no
+// record, or it isn't the feed's URL and its feed has changed.
+func (c *feedChecks) resolve(input string) (resolvedInput, bool) {
+	if c == nil {
+		return resolvedInput{}, false
+	}
+	check, ok := c.lookup(input)
+	if !ok || check.Validators.IsZero() {
+		return resolvedInput{}, false
+	}
+	info, episodes, err := podcast.LoadFeedIfModified(check.FeedURL, check.Validators)
+	switch {
+	case errors.Is(err, podcast.ErrNotModified):
+		info := podcast.PodcastInfo{Name: check.Podcast, FeedURL: check.FeedURL}
+		return resolvedInput{info: info, unchangedSince: check.Time}, true
+	case err == nil && input == check.FeedURL:
+		return resolvedInput{info: info, episodes: episodes}, true
+	}
+	return resolvedInput{}, false
+}
+
+// record notes that input's feed has been downloaded in full as info was
+// served, and saves the records. Like the history, this is best-effort.
+func (c *feedChecks) record(input string, info podcast.PodcastInfo) {
+	if c == nil || info.Validators.IsZero() {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := feedCheckKey{input, c.mode, c.dir}
+	c.checks[key] = feedCheck{
+		Input:      input,
+		Mode:       c.mode,
+		Dir:        c.dir,
+		Podcast:    info.Name,
+		FeedURL:    info.FeedURL,
+		Time:       time.Now(),
+		Validators: info.Validators,
+	}
+
+	checks := make([]feedCheck, 0, len(c.checks))
+	for _, check := range c.checks {
+		checks = append(checks, check)
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Time.Before(checks[j].Time) })
+	data, err := json.MarshalIndent(checks, "", "  ")
+	if err != nil {
+		return
+	}
+	path, err := dataPath(feedChecksFile)
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(filepath.Dir(path), 0755) == nil {
+		os.WriteFile(path, append(data, '\n'), 0644)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"podcastdownload/internal/podcast"
+)
+
+// verifyProblem is a recorded download that is no longer intact
+type verifyProblem struct {
+	entry  historyEntry
+	reason string
+}
+
+// runVerify checks that every download the history records under dir is
+// still on disk and no smaller than when it was downloaded. Sizes are
+// compared without the ID3v2 tag, which retagging rewrites, unless the
+// entry predates recording that. Files listed in their folder's
+// --checksum-manifest must also match their SHA-256. With repair, damaged
+// files are downloaded again. It prints a summary per podcast and returns
+// the number of files left missing or damaged.
+func runVerify(dir string, repair bool, opts options) int {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	entries, err := readHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// The latest entry for a path wins; podcasts keep first-seen order
+	latest := make(map[string]historyEntry)
+	var podcasts []string
+	files := make(map[string][]string)
+	for _, e := range entries {
+		rel, err := filepath.Rel(abs, e.Path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if _, seen := latest[e.Path]; !seen {
+			if _, ok := files[e.Podcast]; !ok {
+				podcasts = append(podcasts, e.Podcast)
+			}
+			files[e.Podcast] = append(files[e.Podcast], e.Path)
+		}
+		latest[e.Path] = e
+	}
+	if len(latest) == 0 {
+		fmt.Printf("No downloads recorded under %s.\n", abs)
+		return 0
+	}
+
+	bad := 0
+	manifests := make(map[string]map[string]string) // by folder
+	for _, name := range podcasts {
+		var problems []verifyProblem
+		for _, path := range files[name] {
+			e := latest[path]
+			size, recorded, err := verifiedSize(e)
+			switch {
+			case err != nil:
+				problems = append(problems, verifyProblem{e, "missing"})
+			case size < recorded:
+				problems = append(problems, verifyProblem{e, fmt.Sprintf("truncated: %s of %s", formatSize(size), formatSize(recorded))})
+			default:
+				reason, err := checksumMismatch(path, manifests)
+				if err != nil {
+					reason = fmt.Sprintf("checksum not verified: %v", err)
+				}
+				if reason != "" {
+					problems = append(problems, verifyProblem{e, reason})
+				}
+			}
+		}
+
+		fmt.Printf("==> %s: %d file(s), %d ok, %d damaged\n", name, len(files[name]), len(files[name])-len(problems), len(problems))
+		for _, p := range problems {
+			fmt.Printf("  ✗ %s (%s)\n", p.entry.Path, p.reason)
+			if !repair {
+				bad++
+				continue
+			}
+			if err := redownload(p.entry, opts); err != nil {
+				fmt.Printf("    repair failed: %v\n", err)
+				bad++
+				continue
+			}
+			fmt.Printf("    ✓ downloaded again\n")
+		}
+	}
+
+	if repair {
+		fmt.Printf("\n%d file(s) still damaged\n", bad)
+	} else if bad > 0 {
+		fmt.Printf("\n%d file(s) damaged; run again with --repair to download them again\n", bad)
+	}
+	return bad
+}
+
+// verifiedSize is the size of e's file now, and as recorded, in the terms
+// the entry was recorded in
+func verifiedSize(e historyEntry) (size, recorded int64, err error) {
+	if e.Audio > 0 {
+		size, err = podcast.AudioSize(e.Path)
+		return size, e.Audio, err
+	}
+	fi, err := os.Stat(e.Path)
+	if err != nil {
+		return 0, e.Size, err
+	}
+	return fi.Size(), e.Size, nil
+}
+
+// redownload replaces a missing or truncated file from its recorded URL.
+// The history doesn't keep episode numbers, so the new file is untagged.
+func redownload(e historyEntry, opts options) error {
+	if err := os.Remove(e.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(e.Path), 0755); err != nil {
+		return err
+	}
+	if _, err := podcast.Download(e.Path, e.URL, opts.download); err != nil {
+		return err
+	}
+	recordDownload(podcast.PodcastInfo{Name: e.Podcast}, podcast.Episode{Title: e.Title, AudioURL: e.URL}, e.Path) // history is best-effort
+	return refreshChecksum(e.Path)
+}